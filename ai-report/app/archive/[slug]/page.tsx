@@ -10,10 +10,18 @@ interface ImageData {
   height: number;
 }
 
+interface RelatedLink {
+  text: string;
+  url: string;
+  source: string;
+}
+
 interface NewsItem {
   text: string;
   url: string;
   image?: ImageData;
+  category?: string;
+  related?: RelatedLink[];
 }
 
 interface NewsData {
@@ -119,6 +127,17 @@ export default async function ArchivePage({ params }: { params: Promise<{ slug:
         <a href={item.url} target="_blank" rel="noopener noreferrer">
           {item.text}
         </a>
+        {item.related && item.related.length > 0 && (
+          <ul className="related-links" aria-label={`More coverage of: ${item.text}`}>
+            {item.related.map((link, index) => (
+              <li key={index}>
+                <a href={link.url} target="_blank" rel="noopener noreferrer">
+                  {link.source}: {link.text}
+                </a>
+              </li>
+            ))}
+          </ul>
+        )}
       </div>
     );
   };
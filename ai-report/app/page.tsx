@@ -10,10 +10,18 @@ interface ImageData {
   height: number;
 }
 
+interface RelatedLink {
+  text: string;
+  url: string;
+  source: string;
+}
+
 interface NewsItem {
   text: string;
   url: string;
   image?: ImageData;
+  category?: string;
+  related?: RelatedLink[];
 }
 
 interface NewsData {
@@ -55,6 +63,17 @@ function NewsItemWithImage({ item, priority = false }: { item: NewsItem; priorit
       >
         {item.text}
       </a>
+      {item.related && item.related.length > 0 && (
+        <ul className="related-links" aria-label={`More coverage of: ${item.text}`}>
+          {item.related.map((link, index) => (
+            <li key={index}>
+              <a href={link.url} target="_blank" rel="noopener noreferrer">
+                {link.source}: {link.text}
+              </a>
+            </li>
+          ))}
+        </ul>
+      )}
     </div>
   );
 }
@@ -1,172 +1,541 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/ai-report/aggregator/internal/aggregator"
+	"github.com/ai-report/aggregator/internal/clock"
+	"github.com/ai-report/aggregator/internal/config"
 	"github.com/ai-report/aggregator/internal/sources"
+	"github.com/ai-report/aggregator/internal/store"
 )
 
+// defaultPublicDir is where the live site reads its data from
+const defaultPublicDir = "public"
+
+// defaultRetention is how long the item store remembers an item after it
+// was last seen
+const defaultRetention = 30 * 24 * time.Hour
+
 func main() {
+	sourcesFile := flag.String("sources", envOrDefault("AI_REPORT_SOURCES", "config/sources.yaml"), "path to the sources config file (env AI_REPORT_SOURCES)")
+	cacheDir := flag.String("cache-dir", envOrDefault("AI_REPORT_CACHE_DIR", ".cache"), "directory for state kept between runs (env AI_REPORT_CACHE_DIR)")
+	publicDir := flag.String("public-dir", envOrDefault("AI_REPORT_PUBLIC_DIR", defaultPublicDir), "directory the site data is written to (env AI_REPORT_PUBLIC_DIR)")
+	explain := flag.Int("explain", 0, "log the score breakdown of the top N ranked items")
+	nowFlag := flag.String("now", "", "run as if the current time were this RFC 3339 timestamp")
+	saveRaw := flag.String("save-raw", "", "write the fetched items and run time to this file for later replay")
+	replay := flag.String("replay", "", "rank items saved with -save-raw instead of fetching")
+	offline := flag.Bool("offline", false, "serve every request from the HTTP cache without touching the network")
+	overridesFile := flag.String("overrides", envOrDefault("AI_REPORT_OVERRIDES", "config/overrides.yaml"), "path to the editorial overrides file, if any (env AI_REPORT_OVERRIDES)")
+	flag.Parse()
+
 	log.Println("Starting AI Report news aggregation...")
 
-	// Initialize aggregator
-	agg := aggregator.New()
+	// Replays and runs at another time produce data that is not the live
+	// site's, so they must be pointed somewhere else, are not archived and
+	// leave the item history and caches as they were
+	rerun := *replay != "" || *nowFlag != ""
+	if rerun && filepath.Clean(*publicDir) == defaultPublicDir {
+		log.Fatalf("-replay and -now need a -public-dir (or AI_REPORT_PUBLIC_DIR) other than %q so they do not overwrite the live site", defaultPublicDir)
+	}
 
-	// Configure sources
-	configureSources(agg)
+	// The whole run sees a single instant, so a replay of its raw items at
+	// that instant reproduces its output exactly
+	now := time.Now()
+	var replayed *rawRun
+	if *replay != "" {
+		run, err := loadRawRun(*replay)
+		if err != nil {
+			log.Fatalf("Failed to load replay: %v", err)
+		}
+		replayed = run
+		now = run.Now
+	}
+	if *nowFlag != "" {
+		t, err := time.Parse(time.RFC3339, *nowFlag)
+		if err != nil {
+			log.Fatalf("Invalid -now: %v", err)
+		}
+		now = t
+	}
+	clk := clock.Fixed(now)
 
-	// Fetch news from all sources
-	news, err := agg.FetchAll()
+	// Load source configuration
+	cfg, err := config.Load(*sourcesFile)
 	if err != nil {
-		log.Fatalf("Failed to fetch news: %v", err)
+		log.Fatalf("Invalid sources config: %v", err)
+	}
+
+	scoring, err := scoringPipeline(cfg.Scoring)
+	if err != nil {
+		log.Fatalf("Invalid scoring config in %s: %v", cfg.Path, err)
+	}
+	decay, err := scoreDecay(cfg.Scoring.Decay)
+	if err != nil {
+		log.Fatalf("Invalid scoring config in %s: %v", cfg.Path, err)
+	}
+	categorizer, columns, err := categoryRules(cfg.Categories)
+	if err != nil {
+		log.Fatalf("Invalid categories config in %s: %v", cfg.Path, err)
+	}
+	diversity, err := diversityLimits(cfg.Diversity)
+	if err != nil {
+		log.Fatalf("Invalid diversity config in %s: %v", cfg.Path, err)
+	}
+	headlines, err := headlineRules(cfg.Headlines)
+	if err != nil {
+		log.Fatalf("Invalid headlines config in %s: %v", cfg.Path, err)
+	}
+	overrides, err := editorialOverrides(*overridesFile)
+	if err != nil {
+		log.Fatalf("Invalid overrides: %v", err)
+	}
+
+	// Open the item store. Replays, runs at another time and offline runs
+	// only read it, so they rank against the history the original run saw
+	// without adding to it.
+	storePath := filepath.Join(*cacheDir, "items.db")
+	openStore := store.Open
+	if rerun || *offline {
+		openStore = store.OpenReadOnly
+	}
+	itemStore, err := openStore(storePath)
+	if err != nil {
+		log.Printf("Warning: Running without item history: %v", err)
+	} else {
+		defer itemStore.Close()
+	}
+
+	// Initialize aggregator
+	opts := []aggregator.Option{
+		aggregator.WithTimeout(cfg.Timeout),
+		aggregator.WithSourceTimeout(cfg.SourceTimeout),
+		aggregator.WithScoring(scoring),
+		aggregator.WithDecay(decay),
+		aggregator.WithClock(clk),
+		aggregator.WithCategorizer(categorizer),
+		aggregator.WithColumns(columns),
+		aggregator.WithDiversity(diversity),
+		aggregator.WithOverrides(overrides),
+		aggregator.WithHeadlines(headlines),
+	}
+	if itemStore != nil {
+		opts = append(opts, aggregator.WithStore(itemStore))
+	}
+	if cfg.Clustering.Threshold != nil {
+		opts = append(opts, aggregator.WithClusterThreshold(*cfg.Clustering.Threshold))
+	}
+	if cfg.Clustering.RelatedLinks != nil {
+		opts = append(opts, aggregator.WithRelatedLimit(*cfg.Clustering.RelatedLinks))
+	}
+	agg := aggregator.New(opts...)
+
+	var news []aggregator.RawNewsItem
+	if replayed != nil {
+		news = replayed.Items
+		log.Printf("Replaying %d items from %s at %s", len(news), *replay, now.Format(time.RFC3339))
+	} else {
+		news = fetchNews(agg, cfg, clk, *cacheDir, *publicDir, *offline, rerun || *offline)
+		if *saveRaw != "" {
+			if err := saveRawRun(*saveRaw, &rawRun{Now: now, Items: news}); err != nil {
+				log.Printf("Warning: Failed to save raw items: %v", err)
+			}
+		}
 	}
 
 	// Process and rank news items
 	processedNews := agg.ProcessNews(news)
+	for i, item := range processedNews.Ranked {
+		if i >= *explain {
+			break
+		}
+		log.Printf("#%d %.2f %q (%s, %s): %s", i+1, item.Score, item.Title, item.Source, item.Category, item.ScoreBreakdown)
+	}
+
+	// Forget items not seen for a while
+	if itemStore != nil {
+		retention := cfg.Store.Retention
+		if retention == 0 {
+			retention = defaultRetention
+		}
+		if removed, err := itemStore.Prune(now.Add(-retention)); err != nil {
+			log.Printf("Warning: Failed to prune item store: %v", err)
+		} else if removed > 0 {
+			log.Printf("Pruned %d items not seen since %s", removed, now.Add(-retention).Format(time.RFC3339))
+		}
+	}
 
 	// Generate news data structure
-	newsData := generateNewsData(processedNews)
+	newsData := generateNewsData(processedNews, now)
 
 	// Save current news data
-	if err := saveNewsData(newsData); err != nil {
+	if err := saveNewsData(newsData, *publicDir); err != nil {
 		log.Fatalf("Failed to save news data: %v", err)
 	}
 
 	// Archive previous version
-	if err := archiveNewsData(); err != nil {
-		log.Printf("Warning: Failed to archive news data: %v", err)
+	if !rerun {
+		if err := archiveNewsData(*publicDir, now); err != nil {
+			log.Printf("Warning: Failed to archive news data: %v", err)
+		}
 	}
 
 	log.Println("News aggregation completed successfully!")
 }
 
-func configureSources(agg *aggregator.Aggregator) {
-	// RSS Feeds
-	rssFeeds := []sources.RSSFeed{
-		{Name: "MIT Technology Review AI", URL: "https://www.technologyreview.com/feed/", Category: "AI"},
-		{Name: "The Verge AI", URL: "https://www.theverge.com/rss/ai-artificial-intelligence/index.xml"},
-		{Name: "VentureBeat AI", URL: "https://feeds.feedburner.com/venturebeat/SZYF"},
-		{Name: "AI News", URL: "https://www.artificialintelligence-news.com/feed/"},
-		{Name: "OpenAI Blog", URL: "https://openai.com/news/rss.xml"},
-		{Name: "Google AI Blog", URL: "https://blog.google/technology/ai/rss"},
-		{Name: "DeepMind Blog", URL: "https://deepmind.google/blog/rss.xml"},
-		{Name: "Hugging Face Blog", URL: "https://huggingface.co/blog/feed.xml"},
-		{Name: "Simon Willison Blog", URL: "https://simonwillison.net/atom/everything/"},
-		{Name: "Andrej Karpathy Blog", URL: "https://karpathy.github.io/feed.xml"},
-		{Name: "Microsoft AI Blog", URL: "https://blogs.microsoft.com/ai/feed/"},
-		{Name: "Machine Learning Mastery", URL: "https://machinelearningmastery.com/feed/"},
-		{Name: "Towards Data Science", URL: "https://towardsdatascience.com/feed"},
-		{Name: "MIT News AI", URL: "https://news.mit.edu/topic/mitartificial-intelligence2-rss.xml"},
-		{Name: "arXiv cs.AI", URL: "https://rss.arxiv.org/rss/cs.AI"},
-		{Name: "arXiv cs.LG", URL: "https://rss.arxiv.org/rss/cs.LG"},
-		{Name: "arXiv cs.CL", URL: "https://rss.arxiv.org/rss/cs.CL"},
-		{Name: "BAIR Blog", URL: "https://bair.berkeley.edu/blog/feed.xml"},
-		{Name: "AI Trends", URL: "https://www.aitrends.com/feed/"},
-		{Name: "DailyAI", URL: "https://dailyai.com/feed/"},
-	}
-
-	for _, feed := range rssFeeds {
-		agg.AddSource(sources.NewRSSSource(feed))
-	}
-
-	// Blogs without RSS feeds (need web scraping)
-	blogSites := []sources.WebScraper{
-		{Name: "Hamel Husain Blog", URL: "https://hamel.dev/"},
-		{Name: "Shreya Shankar Blog", URL: "https://www.shreya-shankar.com/"},
-		{Name: "Jason Liu Blog", URL: "https://jxnl.github.io/blog"},
-		{Name: "Eugene Yan Blog", URL: "https://eugeneyan.com/"},
-		{Name: "Omar Khattab Blog", URL: "https://omarkhattab.com/"},
-		{Name: "Chip Huyen", URL: "https://huyenchip.com/blog"},
-		{Name: "Kwindla Hultman-Kramer Blog", URL: "https://www.daily.co/blog/author/kwindla-hultman-kramer/"},
-		{Name: "Jo Kristian Bergum Blog", URL: "https://blog.vespa.ai/authors/jobergum/"},
-		{Name: "Jason Liu Blog", URL: "https://jxnl.co/writing/"},
-		{Name: "Vespa AI Blog", URL: "https://blog.vespa.ai/"},
-		{Name: "The Batch", URL: "https://www.deeplearning.ai/the-batch/"},
-		{Name: "Unite.AI", URL: "https://www.unite.ai/"},
-		{Name: "Gwern", URL: "https://gwern.net"},
-		{Name: "Anthropic News", URL: "https://www.anthropic.com/news"},
-	}
-
-	// Additional RSS feeds for some blogs
-	additionalRSSFeeds := []sources.RSSFeed{
-		{Name: "Han Chung Lee Blog", URL: "https://leehanchung.github.io/feed.xml"},
-		{Name: "Daily.co Blog", URL: "https://www.daily.co/blog/rss/"},
-		{Name: "Nathan Lambert", URL: "https://www.interconnects.ai/feed"},
-		{Name: "Ethan Mollick", URL: "https://www.oneusefulthing.org/feed"},
-		{Name: "AI Snake Oil", URL: "https://www.aisnakeoil.com/feed"},
-		{Name: "LessWrong", URL: "https://www.lesswrong.com/feed.xml"},
-		{Name: "AI Alignment Forum", URL: "https://www.alignmentforum.org/feed.xml"},
-		{Name: "Distill", URL: "https://distill.pub/rss.xml"},
-		{Name: "The Gradient", URL: "https://thegradient.pub/rss/"},
-		{Name: "Import AI", URL: "https://jack-clark.net/feed/"},
-	}
-
-	for _, feed := range additionalRSSFeeds {
-		agg.AddSource(sources.NewRSSSource(feed))
-	}
-
-	for _, site := range blogSites {
-		agg.AddSource(sources.NewWebScraperSource(site))
-	}
-
-	// Hacker News
-	agg.AddSource(sources.NewHackerNewsSource([]string{
-		"artificial intelligence",
-		"machine learning",
-		"GPT",
-		"LLM",
-		"neural network",
-		"deep learning",
-		"AI safety",
-		"AGI",
-	}))
-
-	// Reddit sources
-	agg.AddSource(sources.NewRedditSource([]string{
-		"MachineLearning",
-		"artificial",
-		"singularity",
-		"OpenAI",
-		"LocalLLaMA",
-	}))
-
-	// Twitter/X accounts (using nitter instances for RSS)
-	twitterAccounts := []sources.TwitterAccount{
-		{Handle: "OpenAI", URL: "https://nitter.net/OpenAI/rss"},
-		{Handle: "AnthropicAI", URL: "https://nitter.net/AnthropicAI/rss"},
-		{Handle: "GoogleAI", URL: "https://nitter.net/GoogleAI/rss"},
-		{Handle: "DeepMind", URL: "https://nitter.net/DeepMind/rss"},
-		{Handle: "elonmusk", URL: "https://nitter.net/elonmusk/rss"},
-		{Handle: "sama", URL: "https://nitter.net/sama/rss"},
-		{Handle: "GaryMarcus", URL: "https://nitter.net/GaryMarcus/rss"},
-		{Handle: "ylecun", URL: "https://nitter.net/ylecun/rss"},
-	}
-
-	for _, account := range twitterAccounts {
-		agg.AddSource(sources.NewTwitterSource(account))
-	}
-
-	// Tech news sites
-	techSites := []sources.WebScraper{
-		{Name: "TechCrunch AI", URL: "https://techcrunch.com/category/artificial-intelligence/"},
-		{Name: "Ars Technica AI", URL: "https://arstechnica.com/ai/"},
-		{Name: "Wired AI", URL: "https://www.wired.com/tag/artificial-intelligence/"},
-	}
-
-	for _, site := range techSites {
-		agg.AddSource(sources.NewWebScraperSource(site))
+// fetchNews fetches every configured source, saving source health and,
+// unless readOnly, the feed and HTTP caches whether or not the fetch
+// succeeds. Offline, every request is served from the HTTP cache.
+func fetchNews(agg *aggregator.Aggregator, cfg *config.Config, clk clock.Clock, cacheDir, publicDir string, offline, readOnly bool) []aggregator.RawNewsItem {
+	// Load feeds discovered for scraped sites on earlier runs
+	feeds, err := sources.LoadFeedCache(filepath.Join(cacheDir, "feeds.json"))
+	if err != nil {
+		log.Printf("Warning: Starting with an empty feed cache: %v", err)
+	}
+	// Load the responses of earlier runs for conditional requests
+	httpCache, err := sources.LoadHTTPCache(filepath.Join(cacheDir, "http"))
+	if err != nil {
+		log.Printf("Warning: Starting with an empty HTTP cache: %v", err)
+	}
+	if offline {
+		log.Printf("Offline: serving %d cached responses from %s", len(httpCache.Entries), filepath.Join(cacheDir, "http"))
+	}
+	limits, err := fetchLimits(cfg.Fetch)
+	if err != nil {
+		log.Fatalf("Invalid fetch config in %s: %v", cfg.Path, err)
+	}
+	bot, err := scraperBot(cfg.Scraper)
+	if err != nil {
+		log.Fatalf("Invalid scraper config in %s: %v", cfg.Path, err)
+	}
+	retry, err := retryPolicies(cfg.Retry)
+	if err != nil {
+		log.Fatalf("Invalid retry config in %s: %v", cfg.Path, err)
+	}
+	fetcher := sources.NewFetcher(limits, httpCache, offline)
+	env := &sources.Env{
+		Feeds:  feeds,
+		Clock:  clk,
+		HTTP:   fetcher,
+		Robots: sources.NewRobots(bot, fetcher),
+		Retry:  retry,
+	}
+
+	// Configure sources
+	if err := configureSources(agg, cfg, env); err != nil {
+		log.Fatalf("Invalid sources config:\n%v", err)
+	}
+	log.Printf("Configured %d sources from %s", len(cfg.Sources), cfg.Path)
+
+	// Fetch news from all sources, stopping early on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	news, report, err := agg.FetchAll(ctx)
+
+	// Save source health even when the run failed, so dead feeds are visible
+	if report != nil {
+		log.Printf("Fetched %d items: %d sources ok, %d failed, %d timed out, %d skipped",
+			len(news), report.OK, report.Failed, report.TimedOut, report.Skipped)
+		hosts := fetcher.Stats()
+		logHostStats(hosts)
+		if err := saveSourceHealth(&sourceHealth{FetchReport: report, Hosts: hosts}, publicDir); err != nil {
+			log.Printf("Warning: Failed to save source health: %v", err)
+		}
+	}
+	if !readOnly {
+		if err := feeds.Save(); err != nil {
+			log.Printf("Warning: Failed to save feed cache: %v", err)
+		}
+		if err := httpCache.Save(); err != nil {
+			log.Printf("Warning: Failed to save HTTP cache: %v", err)
+		}
+	}
+	if err != nil {
+		log.Fatalf("Failed to fetch news: %v", err)
+	}
+
+	return news
+}
+
+// configureSources builds every source listed in the config file and adds
+// it to the aggregator. All invalid entries are reported, not just the first.
+func configureSources(agg *aggregator.Aggregator, cfg *config.Config, env *sources.Env) error {
+	var errs []error
+	for i := range cfg.Sources {
+		source, err := sources.Build(&cfg.Sources[i], env)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		agg.AddSourceWithTimeout(source, cfg.Sources[i].Timeout)
 	}
+
+	return errors.Join(errs...)
 }
 
-func generateNewsData(news *aggregator.ProcessedNews) *NewsData {
-	now := time.Now()
+// scoringPipeline builds the default scoring pipeline adjusted by the
+// scoring section of the config file
+func scoringPipeline(cfg config.Scoring) (aggregator.Pipeline, error) {
+	pipeline := aggregator.DefaultPipeline()
+
+	if len(cfg.Keywords) > 0 {
+		pipeline = pipeline.Replace(aggregator.NewKeywordScorer(cfg.Keywords))
+	}
+	if len(cfg.TrustedSources) > 0 {
+		pipeline = pipeline.Replace(aggregator.NewTrustScorer(cfg.TrustedSources))
+	}
+
+	return pipeline.WithWeights(cfg.Weights)
+}
+
+// scoreDecay builds the default time decay adjusted by the config file
+func scoreDecay(cfg config.Decay) (aggregator.Decay, error) {
+	decay := aggregator.DefaultDecay()
 
+	if cfg.Mode != "" {
+		decay.Mode = aggregator.DecayMode(cfg.Mode)
+	}
+	if cfg.Gravity != 0 {
+		decay.Gravity = cfg.Gravity
+	}
+	if cfg.HalfLife != 0 {
+		decay.HalfLife = cfg.HalfLife
+	}
+	if cfg.MissingAge != 0 {
+		decay.MissingAge = cfg.MissingAge
+	}
+
+	return decay, decay.Validate()
+}
+
+// categoryRules builds the default categoriser and column layout adjusted
+// by the categories section of the config file
+func categoryRules(cfg config.Categories) (aggregator.Categorizer, aggregator.Columns, error) {
+	categorizer := aggregator.DefaultCategorizer()
+	columns := aggregator.DefaultColumns()
+
+	if cfg.Default != "" {
+		categorizer.Default = aggregator.Category(cfg.Default)
+	}
+	for name, rule := range cfg.Rules {
+		category, err := aggregator.ParseCategory(name)
+		if err != nil {
+			return categorizer, columns, err
+		}
+		merged := categorizer.Rules[category]
+		if len(rule.Keywords) > 0 {
+			merged.Keywords = rule.Keywords
+		}
+		if len(rule.Domains) > 0 {
+			merged.Domains = rule.Domains
+		}
+		categorizer.Rules[category] = merged
+	}
+
+	for _, column := range []struct {
+		names []string
+		dst   *[]aggregator.Category
+	}{
+		{cfg.Columns.Left, &columns.Left},
+		{cfg.Columns.Center, &columns.Center},
+		{cfg.Columns.Right, &columns.Right},
+	} {
+		if column.names == nil {
+			continue
+		}
+		*column.dst = make([]aggregator.Category, len(column.names))
+		for i, name := range column.names {
+			(*column.dst)[i] = aggregator.Category(name)
+		}
+	}
+
+	if err := categorizer.Validate(); err != nil {
+		return categorizer, columns, err
+	}
+	return categorizer, columns, columns.Validate()
+}
+
+// diversityLimits builds the default diversity limits adjusted by the
+// config file
+func diversityLimits(cfg config.Diversity) (aggregator.Diversity, error) {
+	diversity := aggregator.DefaultDiversity()
+
+	for _, limit := range []struct {
+		value *int
+		dst   *int
+	}{
+		{cfg.TopPerSource, &diversity.TopPerSource},
+		{cfg.TopPerDomain, &diversity.TopPerDomain},
+		{cfg.PerSource, &diversity.PerSource},
+		{cfg.PerDomain, &diversity.PerDomain},
+	} {
+		if limit.value != nil {
+			*limit.dst = *limit.value
+		}
+	}
+
+	return diversity, diversity.Validate()
+}
+
+// fetchLimits builds the default per-host fetch limits adjusted by the
+// config file
+func fetchLimits(cfg config.Fetch) (sources.FetchLimits, error) {
+	limits := sources.DefaultFetchLimits()
+
+	limits.HostLimits = hostLimits(limits.HostLimits, cfg.Concurrency, cfg.RequestsPerSecond)
+	if cfg.MaxRetryAfter != nil {
+		limits.MaxRetryAfter = *cfg.MaxRetryAfter
+	}
+	for host, hostCfg := range cfg.Hosts {
+		base := limits.HostLimits
+		if defaults, ok := limits.Hosts[strings.ToLower(host)]; ok {
+			base = defaults
+		}
+		limits.Hosts[strings.ToLower(host)] = hostLimits(base, hostCfg.Concurrency, hostCfg.RequestsPerSecond)
+	}
+
+	return limits, limits.Validate()
+}
+
+// scraperBot builds the default scraper identity adjusted by the config
+// file
+func scraperBot(cfg config.Scraper) (sources.Bot, error) {
+	bot := sources.DefaultBot()
+
+	if cfg.UserAgent != "" {
+		bot.UserAgent = cfg.UserAgent
+	}
+	if cfg.Contact != "" {
+		bot.Contact = cfg.Contact
+	}
+
+	return bot, bot.Validate()
+}
+
+// retryPolicies builds the retry policy of each source type the config
+// file adjusts, starting from the type's default
+func retryPolicies(cfg map[string]config.Retry) (map[string]sources.RetryPolicy, error) {
+	known := make(map[string]bool)
+	for _, t := range sources.Types() {
+		known[t] = true
+	}
+
+	policies := make(map[string]sources.RetryPolicy)
+	for sourceType, retry := range cfg {
+		if !known[sourceType] {
+			return nil, fmt.Errorf("unknown source type %q (known types: %v)", sourceType, sources.Types())
+		}
+
+		policy := sources.DefaultRetryPolicy(sourceType)
+		if retry.Attempts != nil {
+			policy.Attempts = *retry.Attempts
+		}
+		if retry.BaseDelay != 0 {
+			policy.BaseDelay = retry.BaseDelay
+		}
+		if retry.MaxDelay != 0 {
+			policy.MaxDelay = retry.MaxDelay
+		}
+		if err := policy.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", sourceType, err)
+		}
+		policies[sourceType] = policy
+	}
+
+	return policies, nil
+}
+
+// hostLimits returns base with the limits that are set replaced
+func hostLimits(base sources.HostLimits, concurrency *int, requestsPerSecond *float64) sources.HostLimits {
+	if concurrency != nil {
+		base.Concurrency = *concurrency
+	}
+	if requestsPerSecond != nil {
+		base.RequestsPerSecond = *requestsPerSecond
+	}
+	return base
+}
+
+// headlineRules builds the default headline rules adjusted by the config
+// file
+func headlineRules(cfg config.Headlines) (aggregator.Headlines, error) {
+	headlines := aggregator.DefaultHeadlines()
+
+	headlines.SiteNames = append(headlines.SiteNames, cfg.SiteNames...)
+	for i, r := range cfg.Rewrites {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return headlines, fmt.Errorf("rewrite %d: %w", i+1, err)
+		}
+		headlines.Rewrites = append(headlines.Rewrites, aggregator.Rewrite{Pattern: pattern, Replace: r.Replace})
+	}
+	if cfg.Emphasis.Always != nil {
+		headlines.Emphasis.Always = cfg.Emphasis.Always
+	}
+	if cfg.Emphasis.Topics != nil {
+		headlines.Emphasis.Topics = cfg.Emphasis.Topics
+	}
+	if cfg.Emphasis.MaxLength != nil {
+		headlines.Emphasis.MaxLength = *cfg.Emphasis.MaxLength
+	}
+
+	return headlines, headlines.Validate()
+}
+
+// editorialOverrides loads the overrides file at path
+func editorialOverrides(path string) (aggregator.Overrides, error) {
+	var overrides aggregator.Overrides
+
+	cfg, err := config.LoadOverrides(path)
+	if err != nil {
+		return overrides, err
+	}
+
+	for i, p := range cfg.Pins {
+		slot, err := aggregator.ParseSlot(p.Slot)
+		if err != nil {
+			return overrides, fmt.Errorf("%s: pin %d: %w", path, i+1, err)
+		}
+		overrides.Pins = append(overrides.Pins, aggregator.Pin{
+			URL: p.URL, Slot: slot, Title: p.Title, Expires: p.Expires, Note: p.Note,
+		})
+	}
+	for _, b := range cfg.Boosts {
+		overrides.Boosts = append(overrides.Boosts, aggregator.Boost{
+			URL: b.URL, Keyword: b.Keyword, Delta: b.Delta, Expires: b.Expires, Note: b.Note,
+		})
+	}
+	for i, b := range cfg.Bans {
+		ban := aggregator.Ban{URL: b.URL, Domain: b.Domain, Expires: b.Expires, Note: b.Note}
+		if b.Title != "" {
+			if ban.Title, err = regexp.Compile(b.Title); err != nil {
+				return overrides, fmt.Errorf("%s: ban %d: %w", path, i+1, err)
+			}
+		}
+		overrides.Bans = append(overrides.Bans, ban)
+	}
+
+	if n := len(overrides.Pins) + len(overrides.Boosts) + len(overrides.Bans); n > 0 {
+		log.Printf("Loaded %d editorial overrides from %s", n, path)
+	}
+	return overrides, nil
+}
+
+func generateNewsData(news *aggregator.ProcessedNews, now time.Time) *NewsData {
 	return &NewsData{
 		MainHeadline: news.TopStory,
 		TopStories:   news.TopStories[:min(3, len(news.TopStories))],
@@ -177,7 +546,7 @@ func generateNewsData(news *aggregator.ProcessedNews) *NewsData {
 	}
 }
 
-func saveNewsData(data *NewsData) error {
+func saveNewsData(data *NewsData, publicDir string) error {
 	// Marshal to JSON
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
@@ -185,7 +554,6 @@ func saveNewsData(data *NewsData) error {
 	}
 
 	// Save to public directory
-	publicDir := "public"
 	if err := os.MkdirAll(publicDir, 0755); err != nil {
 		return fmt.Errorf("failed to create public directory: %w", err)
 	}
@@ -198,8 +566,56 @@ func saveNewsData(data *NewsData) error {
 	return nil
 }
 
-func archiveNewsData() error {
-	publicDir := "public"
+// sourceHealth is the fetch report written to source-health.json, with
+// the requests made to each host
+type sourceHealth struct {
+	*aggregator.FetchReport
+	Hosts map[string]sources.HostStats `json:"hosts,omitempty"`
+}
+
+// logHostStats logs the request totals of the run and the hosts that
+// throttled it
+func logHostStats(hosts map[string]sources.HostStats) {
+	var total sources.HostStats
+	names := make([]string, 0, len(hosts))
+	for name, stats := range hosts {
+		total.Requests += stats.Requests
+		total.NotModified += stats.NotModified
+		total.Errors += stats.Errors
+		names = append(names, name)
+	}
+	log.Printf("Made %d requests to %d hosts: %d not modified, %d failed",
+		total.Requests, len(hosts), total.NotModified, total.Errors)
+
+	sort.Strings(names)
+	for _, name := range names {
+		if stats := hosts[name]; stats.Throttled > 0 {
+			log.Printf("Host %s throttled %d of %d requests", name, stats.Throttled, stats.Requests)
+		}
+	}
+}
+
+// saveSourceHealth writes the per-source fetch report to source-health.json
+// in the public directory
+func saveSourceHealth(report *sourceHealth, publicDir string) error {
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal source health: %w", err)
+	}
+
+	if err := os.MkdirAll(publicDir, 0755); err != nil {
+		return fmt.Errorf("failed to create public directory: %w", err)
+	}
+
+	healthFile := filepath.Join(publicDir, "source-health.json")
+	if err := os.WriteFile(healthFile, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write source health: %w", err)
+	}
+
+	return nil
+}
+
+func archiveNewsData(publicDir string, now time.Time) error {
 	archiveDir := filepath.Join(publicDir, "archive")
 
 	// Create archive directory
@@ -220,7 +636,7 @@ func archiveNewsData() error {
 	}
 
 	// Generate archive filename with timestamp
-	timestamp := time.Now().Format("2006-01-02-15-04-05")
+	timestamp := now.Format("2006-01-02-15-04-05")
 	archiveFile := filepath.Join(archiveDir, fmt.Sprintf("news-data-%s.json", timestamp))
 
 	// Write to archive
@@ -234,6 +650,9 @@ func archiveNewsData() error {
 	return nil
 }
 
+// cleanupOldArchives removes archives written more than 7 days ago. File
+// times are wall-clock times, so they are compared with the wall clock
+// rather than the run's time.
 func cleanupOldArchives(archiveDir string) {
 	cutoff := time.Now().AddDate(0, 0, -7)
 
@@ -259,6 +678,14 @@ func cleanupOldArchives(archiveDir string) {
 	}
 }
 
+// envOrDefault returns the value of the environment variable key, or def if unset
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
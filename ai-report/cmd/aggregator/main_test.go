@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+	"github.com/ai-report/aggregator/internal/config"
+)
+
+func TestConfigureSourcesReportsEveryEntry(t *testing.T) {
+	cfg, err := config.Parse("config.yaml", []byte(`sources:
+  - type: atom
+    name: Unknown type
+  - type: rss
+    url: https://example.com/feed
+  - type: rss
+    name: Fine
+    url: https://example.com/feed
+  - type: scraper
+    name: No URL
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = configureSources(aggregator.New(), cfg, nil)
+	if err == nil {
+		t.Fatal("configureSources accepted bad sources")
+	}
+	for _, want := range []string{
+		`config.yaml:2: unknown source type "atom"`,
+		"config.yaml:4: rss source is missing a name",
+		"config.yaml:9: scraper source is missing a url",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not report %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+)
+
+// rawRun is a run's fetched items along with the instant the run used as
+// "now". Ranking the same items at the same instant with the same config
+// reproduces the run's news-data.json byte for byte.
+type rawRun struct {
+	Now   time.Time                `json:"now"`
+	Items []aggregator.RawNewsItem `json:"items"`
+}
+
+// saveRawRun writes run to path
+func saveRawRun(path string, run *rawRun) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal raw items: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create raw items directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// loadRawRun reads a run saved with saveRawRun
+func loadRawRun(path string) (*rawRun, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read raw items: %w", err)
+	}
+
+	var run rawRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse raw items %s: %w", path, err)
+	}
+	if run.Now.IsZero() {
+		return nil, fmt.Errorf("raw items %s do not record the run time", path)
+	}
+	return &run, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+	"github.com/ai-report/aggregator/internal/clock"
+)
+
+// rankAndSave ranks items at now as a run would and returns the
+// news-data.json it writes to dir
+func rankAndSave(t *testing.T, items []aggregator.RawNewsItem, now time.Time, dir string) []byte {
+	t.Helper()
+	agg := aggregator.New(aggregator.WithClock(clock.Fixed(now)))
+	if err := saveNewsData(generateNewsData(agg.ProcessNews(items), now), dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "news-data.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestReplayReproducesRun(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 123456789, time.FixedZone("CEST", 2*60*60))
+	var items []aggregator.RawNewsItem
+	for i := 0; i < 30; i++ {
+		item := aggregator.RawNewsItem{
+			Title:       fmt.Sprintf("Story %d about open weights and new funding", i),
+			URL:         fmt.Sprintf("https://site%d.example.com/post/%d?utm_source=feed", i%4, i),
+			Description: "A description long enough to count towards similarity.",
+			PublishedAt: now.Add(-time.Duration(i) * 47 * time.Minute),
+			Source:      fmt.Sprintf("Source %d", i%5),
+			ImageURL:    "https://cdn.example.com/img.png",
+		}
+		switch i % 3 {
+		case 0:
+			item.Engagement = aggregator.Engagement{Platform: aggregator.PlatformHackerNews, Points: 40 * i, Comments: 7 * i}
+		case 1:
+			item.Engagement = aggregator.Engagement{Platform: aggregator.PlatformReddit, Points: 13 * i, Comments: i, UpvoteRatio: 0.91}
+			item.Tags = []string{"Research"}
+		}
+		items = append(items, item)
+	}
+
+	dir := t.TempDir()
+	rawPath := filepath.Join(dir, "raw", "run.json")
+	if err := saveRawRun(rawPath, &rawRun{Now: now, Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	original := rankAndSave(t, items, now, filepath.Join(dir, "run"))
+
+	run, err := loadRawRun(rawPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := rankAndSave(t, run.Items, run.Now, filepath.Join(dir, "replay"))
+
+	if !bytes.Equal(original, replayed) {
+		t.Errorf("replayed news-data.json differs from the run's:\nrun:\n%s\nreplay:\n%s", original, replayed)
+	}
+}
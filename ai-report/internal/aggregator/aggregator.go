@@ -1,29 +1,113 @@
 package aggregator
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/url"
 	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/ai-report/aggregator/internal/clock"
+	"github.com/ai-report/aggregator/internal/store"
 )
 
 // Source interface that all news sources must implement
 type Source interface {
+	FetchNews(ctx context.Context) ([]RawNewsItem, error)
+	GetName() string
+}
+
+// LegacySource is the original, context-free source interface
+type LegacySource interface {
 	FetchNews() ([]RawNewsItem, error)
 	GetName() string
 }
 
+// AdaptLegacy wraps a LegacySource so it satisfies Source. A legacy fetch
+// cannot be interrupted, so on cancellation the adapter returns ctx.Err()
+// immediately and the fetch result is discarded when it eventually arrives.
+func AdaptLegacy(source LegacySource) Source {
+	return legacyAdapter{source}
+}
+
+type legacyAdapter struct {
+	LegacySource
+}
+
+func (l legacyAdapter) FetchNews(ctx context.Context) ([]RawNewsItem, error) {
+	return runWithContext(ctx, func() ([]RawNewsItem, error) {
+		return l.LegacySource.FetchNews()
+	})
+}
+
+// fetchResult carries the outcome of a single fetch between goroutines
+type fetchResult struct {
+	items []RawNewsItem
+	err   error
+}
+
+// runWithContext runs fetch in its own goroutine and returns early with
+// ctx.Err() if the context is done before fetch returns
+func runWithContext(ctx context.Context, fetch func() ([]RawNewsItem, error)) ([]RawNewsItem, error) {
+	done := make(chan fetchResult, 1)
+	go func() {
+		items, err := fetch()
+		done <- fetchResult{items, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.items, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // RawNewsItem represents a news item from any source
 type RawNewsItem struct {
 	Title       string
 	URL         string
 	Description string
+
+	// CanonicalURL is the page's own <link rel="canonical">, when the
+	// source saw it. It takes precedence over URL once canonicalised.
+	CanonicalURL string
+
 	PublishedAt time.Time
 	Source      string
+	Author      string
 	ImageURL    string
+	Engagement  Engagement
+
+	// Tags are labels the source filed the item under, such as a Reddit
+	// post's flair. The categoriser reads them alongside the title.
+	Tags []string
+
 	Score       float64 // Relevance score
+
+	// FirstSeen is when the item store first saw the item, set by
+	// ProcessNews when it has a store. Items whose PublishedAt is missing
+	// or suspect are re-dated to it.
+	FirstSeen time.Time
+
+	// SourceCategory is the category configured for the source, a hint
+	// for the categoriser. Category is the one ProcessNews settles on.
+	SourceCategory Category
+	Category       Category
+
+	// ScoreBreakdown holds each scorer's weighted share of Score
+	ScoreBreakdown ScoreBreakdown
+
+	// Coverage is the number of distinct sources carrying the story, and
+	// AlsoCoveredBy the other sources' items folded into this one. Both
+	// are set by ProcessNews.
+	Coverage      int
+	AlsoCoveredBy []Coverage
+
+	cluster int
 }
 
 // ProcessedNews represents categorized news items
@@ -33,15 +117,31 @@ type ProcessedNews struct {
 	LeftColumn   []NewsItem
 	CenterColumn []NewsItem
 	RightColumn  []NewsItem
+
+	// Ranked lists the scored, deduplicated items in rank order, with
+	// their score breakdowns, for debugging the ranking
+	Ranked []RawNewsItem
 }
 
 // NewsItem represents a formatted news item for output
 type NewsItem struct {
-	Text  string     `json:"text"`
-	URL   string     `json:"url"`
-	Image *ImageData `json:"image,omitempty"`
+	Text     string        `json:"text"`
+	URL      string        `json:"url"`
+	Image    *ImageData    `json:"image,omitempty"`
+	Category Category      `json:"category,omitempty"`
+	Related  []RelatedLink `json:"related,omitempty"`
+}
+
+// RelatedLink is another source's coverage of a headline's story
+type RelatedLink struct {
+	Text   string `json:"text"`
+	URL    string `json:"url"`
+	Source string `json:"source"`
 }
 
+// DefaultRelatedLimit is how many related links a headline carries
+const DefaultRelatedLimit = 3
+
 type ImageData struct {
 	Src    string `json:"src"`
 	Alt    string `json:"alt"`
@@ -51,209 +151,436 @@ type ImageData struct {
 
 // Aggregator manages all news sources
 type Aggregator struct {
-	sources []Source
-	mu      sync.Mutex
+	sources       []sourceEntry
+	timeout       time.Duration
+	sourceTimeout time.Duration
+	scoring       Pipeline
+	decay         Decay
+	clock         clock.Clock
+	threshold     float64
+	relatedLimit  int
+	categorizer   Categorizer
+	columns       Columns
+	diversity     Diversity
+	overrides     Overrides
+	headlines     Headlines
+	store         *store.Store
+	mu            sync.Mutex
+}
+
+// sourceEntry is a registered source with its own fetch deadline
+type sourceEntry struct {
+	source  Source
+	timeout time.Duration
+}
+
+// Option configures an Aggregator
+type Option func(*Aggregator)
+
+// WithTimeout caps the total time FetchAll may take. Zero means no cap.
+func WithTimeout(d time.Duration) Option {
+	return func(a *Aggregator) {
+		a.timeout = d
+	}
+}
+
+// WithSourceTimeout sets the fetch deadline for sources added without
+// their own timeout. Zero means sources are only bound by the run deadline.
+func WithSourceTimeout(d time.Duration) Option {
+	return func(a *Aggregator) {
+		a.sourceTimeout = d
+	}
+}
+
+// WithScoring replaces the default scoring pipeline
+func WithScoring(p Pipeline) Option {
+	return func(a *Aggregator) {
+		a.scoring = p
+	}
+}
+
+// WithDecay replaces the default time decay
+func WithDecay(d Decay) Option {
+	return func(a *Aggregator) {
+		a.decay = d
+	}
+}
+
+// WithClock sets the clock items are ranked against
+func WithClock(c clock.Clock) Option {
+	return func(a *Aggregator) {
+		a.clock = c
+	}
+}
+
+// WithClusterThreshold sets the title and description similarity, between
+// 0 and 1, at which items are treated as the same story. Zero limits
+// clustering to items with the same URL or title.
+func WithClusterThreshold(t float64) Option {
+	return func(a *Aggregator) {
+		a.threshold = t
+	}
+}
+
+// WithRelatedLimit caps the related links listed under each headline.
+// Zero leaves them out.
+func WithRelatedLimit(n int) Option {
+	return func(a *Aggregator) {
+		a.relatedLimit = n
+	}
+}
+
+// WithCategorizer replaces the default categorisation rules
+func WithCategorizer(c Categorizer) Option {
+	return func(a *Aggregator) {
+		a.categorizer = c
+	}
+}
+
+// WithColumns sets which categories each output column shows
+func WithColumns(c Columns) Option {
+	return func(a *Aggregator) {
+		a.columns = c
+	}
+}
+
+// WithDiversity sets how many items one source or site may place in each
+// section of the page
+func WithDiversity(d Diversity) Option {
+	return func(a *Aggregator) {
+		a.diversity = d
+	}
+}
+
+// WithOverrides applies editorial pins, boosts and bans when processing
+func WithOverrides(o Overrides) Option {
+	return func(a *Aggregator) {
+		a.overrides = o
+	}
+}
+
+// WithHeadlines replaces the default headline cleaning and emphasis rules
+func WithHeadlines(h Headlines) Option {
+	return func(a *Aggregator) {
+		a.headlines = h
+	}
+}
+
+// WithStore remembers items across runs in s, so items without a
+// believable date are ranked by when they were first seen
+func WithStore(s *store.Store) Option {
+	return func(a *Aggregator) {
+		a.store = s
+	}
 }
 
 // New creates a new Aggregator
-func New() *Aggregator {
-	return &Aggregator{
-		sources: make([]Source, 0),
+func New(opts ...Option) *Aggregator {
+	a := &Aggregator{
+		sources: make([]sourceEntry, 0),
+		scoring: DefaultPipeline(),
+		decay:   DefaultDecay(),
+		clock:   clock.Real,
+
+		threshold:    DefaultClusterThreshold,
+		relatedLimit: DefaultRelatedLimit,
+		categorizer:  DefaultCategorizer(),
+		columns:      DefaultColumns(),
+		diversity:    DefaultDiversity(),
+		headlines:    DefaultHeadlines(),
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 // AddSource adds a news source to the aggregator
 func (a *Aggregator) AddSource(source Source) {
+	a.AddSourceWithTimeout(source, 0)
+}
+
+// AddSourceWithTimeout adds a news source with its own fetch deadline,
+// overriding the aggregator-wide source timeout when non-zero
+func (a *Aggregator) AddSourceWithTimeout(source Source, timeout time.Duration) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.sources = append(a.sources, source)
+	a.sources = append(a.sources, sourceEntry{source: source, timeout: timeout})
 }
 
-// FetchAll fetches news from all sources concurrently
-func (a *Aggregator) FetchAll() ([]RawNewsItem, error) {
-	var wg sync.WaitGroup
-	results := make(chan []RawNewsItem, len(a.sources))
-	errors := make(chan error, len(a.sources))
+// FetchAll fetches news from all sources concurrently. Sources that miss
+// their deadline are reported as timed out; the items of every other
+// source are still returned. The run fails only when every source failed
+// or timed out. The report lists every source in the order it was added.
+func (a *Aggregator) FetchAll(ctx context.Context) ([]RawNewsItem, *FetchReport, error) {
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
 
-	for _, source := range a.sources {
+	// Durations are measured on the system clock; only the reported start
+	// time follows the aggregator's clock
+	start := time.Now()
+	report := &FetchReport{
+		StartedAt: a.clock.Now(),
+		Sources:   make([]SourceReport, len(a.sources)),
+	}
+	results := make([][]RawNewsItem, len(a.sources))
+
+	var wg sync.WaitGroup
+	for i, entry := range a.sources {
 		wg.Add(1)
-		go func(s Source) {
+		go func(i int, e sourceEntry) {
 			defer wg.Done()
-			news, err := s.FetchNews()
+			stats := &fetchStats{}
+			start := time.Now()
+			news, err := a.fetchSource(withFetchStats(ctx, stats), e)
+			report.Sources[i] = newSourceReport(e.source.GetName(), len(news), err, time.Since(start), stats)
+
 			if err != nil {
-				log.Printf("Error fetching from %s: %v", s.GetName(), err)
-				errors <- err
+				switch report.Sources[i].Status {
+				case StatusTimeout:
+					log.Printf("Timed out fetching from %s", e.source.GetName())
+				case StatusSkipped:
+					log.Printf("%s: %v", e.source.GetName(), err)
+				default:
+					log.Printf("Error fetching from %s: %v", e.source.GetName(), err)
+				}
 				return
 			}
-			results <- news
-		}(source)
+			results[i] = news
+		}(i, entry)
 	}
-
 	wg.Wait()
-	close(results)
-	close(errors)
+	report.DurationMS = time.Since(start).Milliseconds()
 
 	// Collect all news items
 	var allNews []RawNewsItem
-	for news := range results {
+	for _, news := range results {
 		allNews = append(allNews, news...)
 	}
 
-	// Check if we had any errors
-	var errs []error
-	for err := range errors {
-		errs = append(errs, err)
+	for _, sr := range report.Sources {
+		switch sr.Status {
+		case StatusOK:
+			report.OK++
+		case StatusTimeout:
+			report.TimedOut++
+		case StatusSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
 	}
 
-	if len(errs) > 0 && len(allNews) == 0 {
-		return nil, fmt.Errorf("failed to fetch news from any source")
+	// Skipped sources chose not to fetch, so they do not fail the run
+	if len(a.sources) > 0 && report.Failed+report.TimedOut == len(a.sources) {
+		return nil, report, fmt.Errorf("failed to fetch news from any source")
 	}
 
-	return allNews, nil
+	return allNews, report, nil
+}
+
+// fetchSource fetches a single source under its deadline. The fetch runs
+// in its own goroutine so a source that ignores its context cannot hold
+// up the run past the deadline.
+func (a *Aggregator) fetchSource(ctx context.Context, e sourceEntry) ([]RawNewsItem, error) {
+	timeout := e.timeout
+	if timeout == 0 {
+		timeout = a.sourceTimeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return runWithContext(ctx, func() ([]RawNewsItem, error) {
+		return e.source.FetchNews(ctx)
+	})
 }
 
 // ProcessNews processes raw news items into categorized format
 func (a *Aggregator) ProcessNews(items []RawNewsItem) *ProcessedNews {
-	// Score and rank items
-	scoredItems := a.scoreItems(items)
-	
-	// Sort by score and recency
-	sort.Slice(scoredItems, func(i, j int) bool {
-		// Prioritize by score, then by recency
-		if scoredItems[i].Score != scoredItems[j].Score {
-			return scoredItems[i].Score > scoredItems[j].Score
-		}
-		return scoredItems[i].PublishedAt.After(scoredItems[j].PublishedAt)
-	})
+	now := a.clock.Now()
+
+	// Strip tracking parameters and redirect wrappers
+	for i := range items {
+		items[i].URL = itemURL(items[i])
+	}
 
-	// Remove duplicates
-	uniqueItems := a.removeDuplicates(scoredItems)
+	// Tidy titles before anything compares them
+	sites := a.headlines.siteNames(items)
+	for i := range items {
+		items[i].Title = a.headlines.Clean(items[i].Title, sites)
+	}
 
-	// Convert to NewsItems
-	newsItems := make([]NewsItem, 0, len(uniqueItems))
-	for _, item := range uniqueItems {
-		newsItem := NewsItem{
-			Text: formatHeadline(item.Title),
-			URL:  item.URL,
-		}
+	// Drop banned items before they can count as coverage
+	items = a.overrides.ban(items, now)
 
-		// Add image if available
-		if item.ImageURL != "" {
-			newsItem.Image = &ImageData{
-				Src:    item.ImageURL,
-				Alt:    item.Title,
-				Width:  600,
-				Height: 400,
-			}
-		}
+	// Date items by their first sighting where their own date fails
+	a.recall(items, now)
 
-		newsItems = append(newsItems, newsItem)
-	}
+	// Group items covering the same story, so coverage can feed ranking
+	setCoverage(items, a.threshold)
 
-	// Categorize news
-	processed := &ProcessedNews{}
-	
-	if len(newsItems) > 0 {
-		processed.TopStory = newsItems[0]
+	// Score and rank items
+	scoredItems := a.rank(items, now)
+	a.remember(scoredItems, now)
+
+	// Keep the best item of each story
+	uniqueItems := collapseClusters(scoredItems)
+
+	// File items under topics
+	for i := range uniqueItems {
+		uniqueItems[i].Category = a.categorizer.Categorize(uniqueItems[i])
 	}
 
-	if len(newsItems) > 1 {
-		end := min(4, len(newsItems))
-		processed.TopStories = newsItems[1:end]
+	// Sections start empty rather than nil so they are written as [] even
+	// when nothing is filed under them
+	processed := &ProcessedNews{
+		Ranked:       uniqueItems,
+		TopStories:   []NewsItem{},
+		LeftColumn:   []NewsItem{},
+		CenterColumn: []NewsItem{},
+		RightColumn:  []NewsItem{},
 	}
 
-	if len(newsItems) > 4 {
-		// Distribute remaining items across columns
-		remaining := newsItems[4:]
-		columnSize := len(remaining) / 3
+	// Take pinned stories out of the ranking; they lead their slots
+	pinned, rest := a.overrides.pin(uniqueItems, now, a.categorizer.Categorize)
 
-		if columnSize > 0 {
-			processed.LeftColumn = remaining[:columnSize]
-			processed.CenterColumn = remaining[columnSize : 2*columnSize]
-			processed.RightColumn = remaining[2*columnSize:]
+	// Fill the headline and top stories in rank order, passing over items
+	// from a source or site already there; they may still make a column
+	limits := newSectionLimits(a.diversity.TopPerSource, a.diversity.TopPerDomain)
+	for _, item := range append(pinned[SlotHeadline], pinned[SlotTop]...) {
+		limits.add(item)
+	}
+	room := topSectionSize - len(pinned[SlotHeadline]) - len(pinned[SlotTop])
+	var ranked, remaining []RawNewsItem
+	for _, item := range rest {
+		if len(ranked) < room && limits.admit(item) {
+			ranked = append(ranked, item)
+			continue
+		}
+		remaining = append(remaining, item)
+	}
+
+	// Pinned top stories go below the headline, whether or not it is pinned
+	var top []RawNewsItem
+	if len(pinned[SlotHeadline]) > 0 {
+		top = append(top, pinned[SlotHeadline]...)
+	} else if len(ranked) > 0 {
+		top, ranked = append(top, ranked[0]), ranked[1:]
+	}
+	top = append(top, pinned[SlotTop]...)
+	top = append(top, ranked...)
+	for i, item := range top {
+		if i == 0 {
+			processed.TopStory = a.newsItem(item)
 		} else {
-			// If not enough for all columns, distribute what we have
-			if len(remaining) > 0 {
-				processed.LeftColumn = remaining[:1]
-			}
-			if len(remaining) > 1 {
-				processed.CenterColumn = remaining[1:2]
-			}
-			if len(remaining) > 2 {
-				processed.RightColumn = remaining[2:]
-			}
+			processed.TopStories = append(processed.TopStories, a.newsItem(item))
+		}
+	}
+
+	// File the rest under the column showing their category, within each
+	// column's source and site limits
+	columns := []*[]NewsItem{&processed.LeftColumn, &processed.CenterColumn, &processed.RightColumn}
+	columnLimits := make([]*sectionLimits, len(columns))
+	for i, slot := range []Slot{SlotLeft, SlotCenter, SlotRight} {
+		columnLimits[i] = newSectionLimits(a.diversity.PerSource, a.diversity.PerDomain)
+		for _, item := range pinned[slot] {
+			columnLimits[i].add(item)
+			*columns[i] = append(*columns[i], a.newsItem(item))
+		}
+	}
+	for _, item := range remaining {
+		col := a.columns.column(item.Category)
+		if col < 0 || !columnLimits[col].admit(item) {
+			continue
 		}
+		*columns[col] = append(*columns[col], a.newsItem(item))
 	}
 
 	return processed
 }
 
-// scoreItems calculates relevance scores for news items
-func (a *Aggregator) scoreItems(items []RawNewsItem) []RawNewsItem {
-	keywords := []string{
-		"GPT", "ChatGPT", "Claude", "Gemini", "LLM", "AI", "artificial intelligence",
-		"machine learning", "deep learning", "neural network", "OpenAI", "Anthropic",
-		"Google AI", "DeepMind", "Microsoft AI", "Meta AI", "AGI", "AI safety",
-		"AI regulation", "AI ethics", "transformer", "diffusion model", "BREAKING",
-		"EXCLUSIVE", "URGENT", "breakthrough", "revolutionary", "unprecedented",
+// newsItem formats a ranked item for output
+func (a *Aggregator) newsItem(item RawNewsItem) NewsItem {
+	newsItem := NewsItem{
+		Text:     a.headlines.Emphasize(item.Title),
+		URL:      item.URL,
+		Category: item.Category,
+		Related:  relatedLinks(item.AlsoCoveredBy, a.relatedLimit),
 	}
 
-	for i := range items {
-		score := 0.0
-		titleLower := strings.ToLower(items[i].Title)
-		descLower := strings.ToLower(items[i].Description)
-
-		// Check keywords
-		for _, keyword := range keywords {
-			keywordLower := strings.ToLower(keyword)
-			if strings.Contains(titleLower, keywordLower) {
-				score += 2.0 // Title matches are worth more
-			}
-			if strings.Contains(descLower, keywordLower) {
-				score += 1.0
-			}
+	// Add image if available
+	if item.ImageURL != "" {
+		newsItem.Image = &ImageData{
+			Src:    item.ImageURL,
+			Alt:    item.Title,
+			Width:  600,
+			Height: 400,
 		}
+	}
 
-		// Boost for recency (last 24 hours)
-		hoursSince := time.Since(items[i].PublishedAt).Hours()
-		if hoursSince < 1 {
-			score += 5.0
-		} else if hoursSince < 6 {
-			score += 3.0
-		} else if hoursSince < 24 {
-			score += 1.0
-		}
+	return newsItem
+}
 
-		// Boost for certain sources
-		trustedSources := []string{"OpenAI", "Anthropic", "Google", "DeepMind", "MIT", "Stanford"}
-		for _, trusted := range trustedSources {
-			if strings.Contains(items[i].Source, trusted) {
-				score += 2.0
-				break
-			}
+// rank scores items as of now and sorts them best first
+func (a *Aggregator) rank(items []RawNewsItem, now time.Time) []RawNewsItem {
+	scoredItems := a.scoreItems(items, now)
+	a.overrides.boost(scoredItems, now)
+
+	// Sort by score and recency
+	sort.SliceStable(scoredItems, func(i, j int) bool {
+		// Prioritize by score, then by recency. Age rather than the raw
+		// date breaks ties, so a bogus future date does not win them.
+		if scoredItems[i].Score != scoredItems[j].Score {
+			return scoredItems[i].Score > scoredItems[j].Score
 		}
+		return a.decay.Age(scoredItems[i].PublishedAt, now) < a.decay.Age(scoredItems[j].PublishedAt, now)
+	})
 
-		items[i].Score = score
-	}
+	return scoredItems
+}
 
+// scoreItems calculates relevance scores for news items and discounts
+// them by age
+func (a *Aggregator) scoreItems(items []RawNewsItem, now time.Time) []RawNewsItem {
+	a.scoring.Score(items, now)
+	a.decay.Apply(items, now)
 	return items
 }
 
-// removeDuplicates removes duplicate news items based on similar titles
-func (a *Aggregator) removeDuplicates(items []RawNewsItem) []RawNewsItem {
-	seen := make(map[string]bool)
-	unique := make([]RawNewsItem, 0)
-
-	for _, item := range items {
-		// Create a normalized key from the title
-		key := normalizeTitle(item.Title)
-		if !seen[key] {
-			seen[key] = true
-			unique = append(unique, item)
+// itemURL returns the canonical form of an item's URL, preferring the
+// page's declared canonical URL when the source found one
+func itemURL(item RawNewsItem) string {
+	if item.CanonicalURL != "" {
+		if u, err := url.Parse(item.CanonicalURL); err == nil && u.IsAbs() && u.Host != "" {
+			return CanonicalURL(item.CanonicalURL)
 		}
 	}
+	return CanonicalURL(item.URL)
+}
+
+// relatedLinks lists up to limit of the other sources covering a story
+func relatedLinks(coverage []Coverage, limit int) []RelatedLink {
+	if limit <= 0 {
+		return nil
+	}
+	if len(coverage) > limit {
+		coverage = coverage[:limit]
+	}
+	if len(coverage) == 0 {
+		return nil
+	}
 
-	return unique
+	links := make([]RelatedLink, len(coverage))
+	for i, c := range coverage {
+		links[i] = RelatedLink{Text: c.Title, URL: c.URL, Source: c.Source}
+	}
+	return links
 }
 
 // normalizeTitle creates a normalized version of a title for duplicate detection
@@ -281,34 +608,4 @@ func normalizeTitle(title string) string {
 	}
 
 	return strings.TrimSpace(title)
-}
-
-// formatHeadline formats a headline in Drudge Report style
-func formatHeadline(title string) string {
-	// Check if it should be all caps (major news)
-	upperKeywords := []string{"BREAKING", "EXCLUSIVE", "URGENT"}
-	for _, keyword := range upperKeywords {
-		if strings.Contains(strings.ToUpper(title), keyword) {
-			return strings.ToUpper(title)
-		}
-	}
-
-	// Check for major companies/topics that warrant caps
-	majorTopics := []string{"GPT-5", "GPT5", "CHATGPT", "OPENAI", "GOOGLE", "MICROSOFT", "META", "APPLE"}
-	titleUpper := strings.ToUpper(title)
-	for _, topic := range majorTopics {
-		if strings.Contains(titleUpper, topic) && len(title) < 60 {
-			return strings.ToUpper(title)
-		}
-	}
-
-	// Otherwise, use title case
-	return title
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
 } 
\ No newline at end of file
@@ -0,0 +1,74 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingLegacy is a context-free source that hangs until release is
+// closed
+type blockingLegacy struct {
+	release chan struct{}
+}
+
+func (b blockingLegacy) FetchNews() ([]RawNewsItem, error) {
+	<-b.release
+	return []RawNewsItem{{Title: "Too late"}}, nil
+}
+
+func (b blockingLegacy) GetName() string { return "Legacy" }
+
+func TestFetchAllCutsOffBlockedSources(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	// Neither a legacy source nor one that ignores its context can hold the
+	// run past the deadline, whether it is the source's or the run's
+	ignoresContext := stubSource{"Stubborn", func(ctx context.Context) ([]RawNewsItem, error) {
+		<-release
+		return nil, nil
+	}}
+	tests := []struct {
+		name string
+		opts []Option
+		add  func(a *Aggregator)
+	}{
+		{"legacy, source deadline", []Option{WithSourceTimeout(100 * time.Millisecond)}, func(a *Aggregator) {
+			a.AddSource(AdaptLegacy(blockingLegacy{release}))
+		}},
+		{"legacy, own deadline", nil, func(a *Aggregator) {
+			a.AddSourceWithTimeout(AdaptLegacy(blockingLegacy{release}), 100*time.Millisecond)
+		}},
+		{"legacy, run deadline", []Option{WithTimeout(100 * time.Millisecond)}, func(a *Aggregator) {
+			a.AddSource(AdaptLegacy(blockingLegacy{release}))
+		}},
+		{"ignores context", []Option{WithSourceTimeout(100 * time.Millisecond)}, func(a *Aggregator) {
+			a.AddSource(ignoresContext)
+		}},
+	}
+
+	for _, tt := range tests {
+		a := New(tt.opts...)
+		tt.add(a)
+		a.AddSource(okSource("Prompt", 2))
+
+		start := time.Now()
+		items, report, err := a.FetchAll(context.Background())
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: FetchAll took %v, want it back by the 100ms deadline", tt.name, elapsed)
+		}
+		if err != nil {
+			t.Fatalf("%s: FetchAll: %v", tt.name, err)
+		}
+		if len(items) != 2 {
+			t.Errorf("%s: got %d items, want the prompt source's 2", tt.name, len(items))
+		}
+		if got := report.Sources[0]; got.Status != StatusTimeout || got.ErrorClass != ErrorClassTimeout {
+			t.Errorf("%s: blocked source reported %s (%s), want timeout", tt.name, got.Status, got.ErrorClass)
+		}
+		if report.Sources[1].Status != StatusOK {
+			t.Errorf("%s: prompt source reported %s, want ok", tt.name, report.Sources[1].Status)
+		}
+	}
+}
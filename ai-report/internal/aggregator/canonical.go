@@ -0,0 +1,164 @@
+package aggregator
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParams are query parameters that identify a campaign or referrer
+// rather than the page. Parameters starting with utm_ are always dropped.
+var trackingParams = map[string]bool{
+	"fbclid":   true,
+	"gclid":    true,
+	"dclid":    true,
+	"msclkid":  true,
+	"yclid":    true,
+	"igshid":   true,
+	"mc_cid":   true,
+	"mc_eid":   true,
+	"_hsenc":   true,
+	"_hsmi":    true,
+	"mkt_tok":  true,
+	"ref":      true,
+	"ref_src":  true,
+	"ref_url":  true,
+	"referrer": true,
+	"source":   true,
+	"cmpid":    true,
+	"smid":     true,
+	"sr_share": true,
+}
+
+// redirectParams maps redirector hosts to the query parameter holding the
+// destination, for links that aggregators and newsletters wrap
+var redirectParams = map[string]string{
+	"out.reddit.com":   "url",
+	"l.facebook.com":   "u",
+	"lm.facebook.com":  "u",
+	"www.google.com":   "q",
+	"google.com":       "q",
+	"l.threads.net":    "u",
+	"www.linkedin.com": "url",
+}
+
+// CanonicalURL returns the address an item should be known by: redirect
+// wrappers are unwrapped, the scheme and host lowercased, default ports,
+// fragments and tracking parameters dropped and the remaining parameters
+// sorted. URLs that do not parse are returned trimmed but otherwise as is.
+func CanonicalURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return strings.TrimSpace(raw)
+	}
+
+	// Follow a few levels of wrapping, such as a Google redirect to a
+	// Facebook redirect
+	for i := 0; i < 3; i++ {
+		target, ok := unwrapRedirect(u)
+		if !ok {
+			break
+		}
+		u = target
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "https" && u.Port() == "443") || (u.Scheme == "http" && u.Port() == "80") {
+		u.Host = u.Hostname()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = encodeSorted(query)
+
+	return u.String()
+}
+
+// unwrapRedirect returns the destination of a known redirect wrapper. The
+// destination must be in the wrapper URL itself: shorteners such as t.co
+// would need a request to resolve and are left alone.
+func unwrapRedirect(u *url.URL) (*url.URL, bool) {
+	host := strings.ToLower(u.Hostname())
+
+	var target string
+	switch {
+	case host == "href.li":
+		// https://href.li/?https://example.com/
+		target = u.RawQuery
+	case host == "news.google.com" && strings.HasPrefix(u.Path, "/url"):
+		target = u.Query().Get("url")
+	default:
+		param, ok := redirectParams[host]
+		if !ok {
+			return nil, false
+		}
+		if strings.HasSuffix(host, "google.com") && u.Path != "/url" {
+			return nil, false
+		}
+		target = u.Query().Get(param)
+	}
+
+	dest, err := url.Parse(target)
+	if err != nil || dest.Host == "" || (dest.Scheme != "http" && dest.Scheme != "https") {
+		return nil, false
+	}
+	return dest, true
+}
+
+// encodeSorted encodes query parameters sorted by key, keeping the order
+// of repeated values
+func encodeSorted(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		for _, value := range query[key] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(key))
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(value))
+		}
+	}
+	return b.String()
+}
+
+// urlKey reduces a canonical URL to the identity used for deduplication:
+// scheme, "www." and "m." host prefixes and trailing slashes are ignored
+func urlKey(canonical string) string {
+	u, err := url.Parse(canonical)
+	if err != nil || u.Host == "" {
+		return canonical
+	}
+
+	host := u.Hostname()
+	for _, prefix := range []string{"www.", "m.", "mobile.", "amp."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	if port := u.Port(); port != "" {
+		host += ":" + port
+	}
+
+	path := strings.TrimRight(u.EscapedPath(), "/")
+	key := host + path
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
@@ -0,0 +1,67 @@
+package aggregator
+
+import "testing"
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		// Tracking parameters go, others are kept and sorted
+		{"https://example.com/post?utm_source=hn&utm_medium=social", "https://example.com/post"},
+		{"https://example.com/post?fbclid=abc&id=7", "https://example.com/post?id=7"},
+		{"https://example.com/post?UTM_Campaign=x&b=2&a=1&gclid=y", "https://example.com/post?a=1&b=2"},
+		{"https://example.com/search?q=a+b&ref=nav", "https://example.com/search?q=a+b"},
+
+		// Fragments, case and default ports
+		{"https://example.com/post#comments", "https://example.com/post"},
+		{"HTTPS://Example.COM/Post", "https://example.com/Post"},
+		{"https://example.com:443/post", "https://example.com/post"},
+		{"http://example.com:80/post", "http://example.com/post"},
+		{"https://example.com:8443/post", "https://example.com:8443/post"},
+		{"  https://example.com/post  ", "https://example.com/post"},
+
+		// Redirect wrappers
+		{"https://www.google.com/url?q=https://example.com/post%3Futm_source%3Dg&sa=D", "https://example.com/post"},
+		{"https://www.google.com/search?q=https://example.com/post", "https://www.google.com/search?q=https%3A%2F%2Fexample.com%2Fpost"},
+		{"https://news.google.com/url?url=https://example.com/post", "https://example.com/post"},
+		{"https://out.reddit.com/t3_abc?url=https%3A%2F%2Fexample.com%2Fpost&token=x", "https://example.com/post"},
+		{"https://href.li/?https://example.com/post", "https://example.com/post"},
+		{"https://l.facebook.com/l.php?u=https%3A%2F%2Fwww.google.com%2Furl%3Fq%3Dhttps%3A%2F%2Fexample.com%2Fpost", "https://example.com/post"},
+		{"https://out.reddit.com/t3_abc?url=javascript:alert(1)", "https://out.reddit.com/t3_abc?url=javascript%3Aalert%281%29"},
+
+		// Not URLs
+		{"not a url", "not a url"},
+		{"/relative/path", "/relative/path"},
+	}
+
+	for _, tt := range tests {
+		if got := CanonicalURL(tt.raw); got != tt.want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestURLKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"https://www.example.com/post", "https://example.com/post", true},
+		{"https://m.example.com/post", "https://example.com/post", true},
+		{"https://amp.example.com/post/", "http://example.com/post", true},
+		{"https://example.com/post/", "https://example.com/post", true},
+		{"https://example.com/", "https://example.com", true},
+		{"https://example.com/post?id=1", "https://example.com/post?id=2", false},
+		{"https://example.com:8080/post", "https://example.com/post", false},
+		{"https://blog.example.com/post", "https://example.com/post", false},
+		{"https://example.com/Post", "https://example.com/post", false},
+	}
+
+	for _, tt := range tests {
+		a, b := urlKey(CanonicalURL(tt.a)), urlKey(CanonicalURL(tt.b))
+		if (a == b) != tt.same {
+			t.Errorf("urlKey(%q) = %q, urlKey(%q) = %q, want same=%v", tt.a, a, tt.b, b, tt.same)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package aggregator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Category is the topic an item is filed under, which decides the column
+// it appears in
+type Category string
+
+const (
+	CategoryResearch   Category = "research"
+	CategoryIndustry   Category = "industry" // products, companies and funding
+	CategoryPolicy     Category = "policy"   // regulation, law and safety
+	CategoryOpenSource Category = "open-source"
+	CategoryOpinion    Category = "opinion"
+)
+
+// Categories lists every category. Ties between categories are broken in
+// this order.
+var Categories = []Category{
+	CategoryResearch,
+	CategoryIndustry,
+	CategoryPolicy,
+	CategoryOpenSource,
+	CategoryOpinion,
+}
+
+// ParseCategory returns the category with the given name
+func ParseCategory(name string) (Category, error) {
+	for _, c := range Categories {
+		if string(c) == name {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown category %q (known categories: %v)", name, Categories)
+}
+
+// Points each kind of evidence adds to a category. A source's own category
+// or a known domain outweighs a single keyword, but not several.
+const (
+	sourceCategoryPoints = 3
+	domainPoints         = 3
+	tagPoints            = 2
+	titleKeywordPoints   = 2
+	descKeywordPoints    = 1
+)
+
+// CategoryRule lists what files an item under a category: keywords in its
+// title or description, and the domains of its URL. A domain also matches
+// its subdomains.
+type CategoryRule struct {
+	Keywords []string
+	Domains  []string
+}
+
+// Categorizer files items under categories by rules. Each category earns
+// points for the item's source category, a matching domain, every tag
+// naming it or one of its keywords, and every keyword found in the title
+// or description; the category with the most points wins, and items
+// earning none are filed under Default.
+type Categorizer struct {
+	Rules   map[Category]CategoryRule
+	Default Category
+}
+
+// DefaultCategorizer returns the built-in rules, filing anything they do
+// not recognise as industry news
+func DefaultCategorizer() Categorizer {
+	return Categorizer{
+		Default: CategoryIndustry,
+		Rules: map[Category]CategoryRule{
+			CategoryResearch: {
+				Keywords: []string{
+					"paper", "papers", "arxiv", "preprint", "study", "researchers",
+					"benchmark", "benchmarks", "dataset", "state of the art", "sota",
+					"neurips", "icml", "iclr", "cvpr", "acl", "technical report",
+					"we propose", "we introduce", "survey", "theorem",
+				},
+				Domains: []string{
+					"arxiv.org", "openreview.net", "paperswithcode.com", "distill.pub",
+					"bair.berkeley.edu", "thegradient.pub", "research.google",
+					"nature.com", "science.org", "aclanthology.org",
+				},
+			},
+			CategoryIndustry: {
+				Keywords: []string{
+					"launch", "launches", "launched", "announces", "announced",
+					"funding", "raises", "raised", "valuation", "acquires",
+					"acquisition", "startup", "revenue", "earnings", "ipo", "pricing",
+					"partnership", "enterprise", "customers", "series a", "series b",
+					"series c", "ceo", "chips", "nvidia",
+				},
+				Domains: []string{
+					"techcrunch.com", "venturebeat.com", "theverge.com",
+					"bloomberg.com", "reuters.com", "cnbc.com", "theinformation.com",
+				},
+			},
+			CategoryPolicy: {
+				Keywords: []string{
+					"regulation", "regulators", "regulate", "law", "lawmakers", "bill",
+					"senate", "congress", "ai act", "eu", "ftc", "lawsuit", "sues",
+					"sued", "court", "copyright", "ban", "bans", "safety", "alignment",
+					"governance", "executive order", "privacy", "deepfake",
+					"deepfakes", "misinformation", "policy", "ethics", "existential risk",
+				},
+				Domains: []string{
+					"lesswrong.com", "alignmentforum.org", "aisnakeoil.com",
+					"whitehouse.gov", "europa.eu",
+				},
+			},
+			CategoryOpenSource: {
+				Keywords: []string{
+					"open source", "open-source", "open weights", "open-weight",
+					"open-weights", "github", "hugging face", "huggingface", "llama",
+					"mistral", "ollama", "llama.cpp", "apache 2.0", "mit license",
+					"self-hosted", "local llm", "gguf",
+				},
+				Domains: []string{"github.com", "gitlab.com", "huggingface.co"},
+			},
+			CategoryOpinion: {
+				Keywords: []string{
+					"opinion", "essay", "thoughts", "why i", "i think", "op-ed",
+					"editorial", "the case for", "the case against", "in defense of",
+					"ask hn", "lessons", "reflections",
+				},
+				Domains: []string{
+					"simonwillison.net", "karpathy.github.io", "gwern.net",
+					"oneusefulthing.org", "interconnects.ai",
+				},
+			},
+		},
+	}
+}
+
+// Validate reports rules or a default naming unknown categories
+func (c Categorizer) Validate() error {
+	if _, err := ParseCategory(string(c.Default)); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for category := range c.Rules {
+		if _, err := ParseCategory(string(category)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Categorize returns the category item belongs in
+func (c Categorizer) Categorize(item RawNewsItem) Category {
+	title := matchText(item.Title)
+	desc := matchText(item.Description)
+	host := itemDomain(item.URL)
+
+	best, bestPoints := c.Default, 0
+	for _, category := range Categories {
+		points := 0
+		if item.SourceCategory == category {
+			points += sourceCategoryPoints
+		}
+
+		rule := c.Rules[category]
+		for _, tag := range item.Tags {
+			if tagMatches(tag, category, rule) {
+				points += tagPoints
+			}
+		}
+		for _, domain := range rule.Domains {
+			domain = strings.ToLower(domain)
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				points += domainPoints
+				break
+			}
+		}
+		for _, keyword := range rule.Keywords {
+			keyword = matchText(keyword)
+			if strings.Contains(title, keyword) {
+				points += titleKeywordPoints
+			}
+			if strings.Contains(desc, keyword) {
+				points += descKeywordPoints
+			}
+		}
+
+		if points > bestPoints {
+			best, bestPoints = category, points
+		}
+	}
+	return best
+}
+
+// tagMatches reports whether tag names category or contains one of its
+// keywords
+func tagMatches(tag string, category Category, rule CategoryRule) bool {
+	tag = matchText(tag)
+	if strings.Contains(tag, matchText(string(category))) {
+		return true
+	}
+	for _, keyword := range rule.Keywords {
+		if strings.Contains(tag, matchText(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchText lowercases text and reduces everything but letters, digits and
+// the hyphens and dots inside words to single spaces, padding the result
+// so whole words can be found by searching for " word "
+func matchText(text string) string {
+	text = strings.ToLower(text)
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '.'
+	})
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, "-.")
+	}
+	return " " + strings.Join(fields, " ") + " "
+}
+
+// Columns maps each output column to the categories it shows. Items below
+// the top stories go to the column showing their category, in rank order;
+// categories left out of every column are not shown there.
+type Columns struct {
+	Left   []Category
+	Center []Category
+	Right  []Category
+}
+
+// DefaultColumns puts research and open source on the left, industry news
+// in the centre and policy and opinion on the right
+func DefaultColumns() Columns {
+	return Columns{
+		Left:   []Category{CategoryResearch, CategoryOpenSource},
+		Center: []Category{CategoryIndustry},
+		Right:  []Category{CategoryPolicy, CategoryOpinion},
+	}
+}
+
+// Validate reports unknown categories and categories shown in more than
+// one column
+func (c Columns) Validate() error {
+	seen := make(map[Category]string)
+	for _, column := range []struct {
+		name       string
+		categories []Category
+	}{{"left", c.Left}, {"center", c.Center}, {"right", c.Right}} {
+		for _, category := range column.categories {
+			if _, err := ParseCategory(string(category)); err != nil {
+				return fmt.Errorf("%s column: %w", column.name, err)
+			}
+			if other, ok := seen[category]; ok {
+				return fmt.Errorf("category %q is in both the %s and %s columns", category, other, column.name)
+			}
+			seen[category] = column.name
+		}
+	}
+	return nil
+}
+
+// column returns the index of the column, 0 to 2, showing category, or -1
+func (c Columns) column(category Category) int {
+	for i, categories := range [][]Category{c.Left, c.Center, c.Right} {
+		for _, shown := range categories {
+			if shown == category {
+				return i
+			}
+		}
+	}
+	return -1
+}
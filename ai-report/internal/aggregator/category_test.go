@@ -0,0 +1,39 @@
+package aggregator
+
+import "testing"
+
+func TestCategorizeReadsTags(t *testing.T) {
+	tests := []struct {
+		name string
+		item RawNewsItem
+		want Category
+	}{
+		{
+			"untagged",
+			RawNewsItem{Title: "Scaling laws for sparse mixture-of-experts models", URL: "https://example.com/post"},
+			CategoryIndustry,
+		},
+		{
+			"tag naming a category",
+			RawNewsItem{Title: "Scaling laws for sparse mixture-of-experts models", URL: "https://example.com/post", Tags: []string{"Research"}},
+			CategoryResearch,
+		},
+		{
+			"tag holding a keyword",
+			RawNewsItem{Title: "A new model for code completion", URL: "https://example.com/post", Tags: []string{"Open Source"}},
+			CategoryOpenSource,
+		},
+		{
+			"tag without either",
+			RawNewsItem{Title: "Scaling laws for sparse mixture-of-experts models", URL: "https://example.com/post", Tags: []string{"Discussion"}},
+			CategoryIndustry,
+		},
+	}
+
+	categorizer := DefaultCategorizer()
+	for _, tt := range tests {
+		if got := categorizer.Categorize(tt.item); got != tt.want {
+			t.Errorf("%s: Categorize = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
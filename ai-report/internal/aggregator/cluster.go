@@ -0,0 +1,266 @@
+package aggregator
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DefaultClusterThreshold is the TF-IDF cosine similarity above which two
+// items are taken to cover the same story
+const DefaultClusterThreshold = 0.5
+
+// descriptionTokens caps how much of a description counts towards
+// similarity; the lede says what a story is about, the rest is detail
+const descriptionTokens = 40
+
+// Coverage is another source's take on a story that clustering folded into
+// the item shown for it
+type Coverage struct {
+	Title  string
+	URL    string
+	Source string
+}
+
+// stopWords are dropped before comparing items
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"how": true, "in": true, "is": true, "it": true, "its": true, "new": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "this": true,
+	"to": true, "was": true, "what": true, "when": true, "who": true, "why": true,
+	"will": true, "with": true, "you": true, "your": true, "here": true,
+	"now": true, "just": true, "about": true, "after": true, "over": true,
+	"into": true, "can": true, "we": true, "our": true, "it's": true,
+	"out": true,
+}
+
+// tokenize splits text into lowercased, lightly stemmed words. Hyphens and
+// dots inside a word are kept so "gpt-4o" and "3.5" stay whole, and
+// possessives are dropped so "OpenAI's" matches "OpenAI".
+func tokenize(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "’", "'")
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '.' && r != '\''
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSuffix(strings.Trim(field, "-.'"), "'s")
+		if field == "" || stopWords[field] {
+			continue
+		}
+		tokens = append(tokens, stem(field))
+	}
+	return tokens
+}
+
+// stem strips common English plural endings so "releases" matches
+// "release" and "launches" matches "launch"
+func stem(word string) string {
+	switch {
+	case len(word) <= 3:
+		return word
+	case strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") &&
+		!strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// termVector is a sparse, L2-normalised TF-IDF vector
+type termVector map[string]float64
+
+// dot is the cosine similarity of two normalised vectors
+func (v termVector) dot(other termVector) float64 {
+	if len(other) < len(v) {
+		v, other = other, v
+	}
+	dot := 0.0
+	for term, weight := range v {
+		dot += weight * other[term]
+	}
+	return dot
+}
+
+// add accumulates other into v
+func (v termVector) add(other termVector) {
+	for term, weight := range other {
+		v[term] += weight
+	}
+}
+
+// norm returns the vector's length
+func (v termVector) norm() float64 {
+	sum := 0.0
+	for _, weight := range v {
+		sum += weight * weight
+	}
+	return math.Sqrt(sum)
+}
+
+// itemVectors builds a TF-IDF vector for each item from its title, counted
+// twice, and the start of its description
+func itemVectors(items []RawNewsItem) []termVector {
+	counts := make([]map[string]float64, len(items))
+	docFreq := make(map[string]int)
+
+	for i, item := range items {
+		tf := make(map[string]float64)
+		for _, token := range tokenize(item.Title) {
+			tf[token] += 2
+		}
+		desc := tokenize(item.Description)
+		if len(desc) > descriptionTokens {
+			desc = desc[:descriptionTokens]
+		}
+		for _, token := range desc {
+			tf[token]++
+		}
+		for token := range tf {
+			docFreq[token]++
+		}
+		counts[i] = tf
+	}
+
+	n := float64(len(items))
+	vectors := make([]termVector, len(items))
+	for i, tf := range counts {
+		vec := make(termVector, len(tf))
+		norm := 0.0
+		for token, count := range tf {
+			idf := math.Log((n+1)/float64(docFreq[token]+1)) + 1
+			w := (1 + math.Log(count)) * idf
+			vec[token] = w
+			norm += w * w
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for token := range vec {
+				vec[token] /= norm
+			}
+		}
+		vectors[i] = vec
+	}
+	return vectors
+}
+
+// clusterItems groups items that cover the same story and returns each
+// item's cluster number. Items sharing a canonical URL or normalised title
+// always share a cluster; otherwise an item joins the cluster whose
+// centroid it is most similar to, if that similarity reaches threshold, or
+// starts a new one. Comparing against the centroid rather than the nearest
+// member keeps loosely related stories from chaining together. A threshold
+// of 0 or less disables the similarity test.
+func clusterItems(items []RawNewsItem, threshold float64) []int {
+	assignments := make([]int, len(items))
+	var centroids []termVector // sum of each cluster's member vectors
+	byURL := make(map[string]int)
+	byTitle := make(map[string]int)
+
+	var vectors []termVector
+	if threshold > 0 {
+		vectors = itemVectors(items)
+	}
+
+	for i, item := range items {
+		cluster := -1
+
+		key := urlKey(item.URL)
+		titleKey := normalizeTitle(item.Title)
+		if c, ok := byURL[key]; ok && item.URL != "" {
+			cluster = c
+		} else if c, ok := byTitle[titleKey]; ok && titleKey != "" {
+			cluster = c
+		} else if threshold > 0 {
+			best := threshold
+			for c, centroid := range centroids {
+				// Rounded so map iteration order, which changes the last
+				// bits of the sums, cannot change the outcome between runs
+				sim := math.Round(vectors[i].dot(centroid)/centroid.norm()*1e9) / 1e9
+				if sim >= best {
+					cluster, best = c, sim
+				}
+			}
+		}
+
+		if cluster < 0 {
+			cluster = len(centroids)
+			centroids = append(centroids, make(termVector))
+		}
+		if vectors != nil {
+			centroids[cluster].add(vectors[i])
+		}
+
+		assignments[i] = cluster
+		if item.URL != "" {
+			if _, ok := byURL[key]; !ok {
+				byURL[key] = cluster
+			}
+		}
+		if _, ok := byTitle[titleKey]; !ok && titleKey != "" {
+			byTitle[titleKey] = cluster
+		}
+	}
+
+	return assignments
+}
+
+// setCoverage clusters items and records on each how many distinct sources
+// cover its story
+func setCoverage(items []RawNewsItem, threshold float64) {
+	clusters := clusterItems(items, threshold)
+
+	sources := make(map[int]map[string]bool)
+	for i, c := range clusters {
+		if sources[c] == nil {
+			sources[c] = make(map[string]bool)
+		}
+		sources[c][items[i].Source] = true
+		items[i].cluster = c
+	}
+	for i := range items {
+		items[i].Coverage = len(sources[items[i].cluster])
+	}
+}
+
+// collapseClusters keeps the first item of each cluster, which in ranked
+// order is the best, and lists the other members on it as also covering
+// the story. Members from the same URL as the representative or from a
+// source already listed are not repeated.
+func collapseClusters(items []RawNewsItem) []RawNewsItem {
+	representative := make(map[int]int) // cluster -> index in kept
+	kept := make([]RawNewsItem, 0, len(items))
+
+	for _, item := range items {
+		idx, ok := representative[item.cluster]
+		if !ok {
+			representative[item.cluster] = len(kept)
+			item.AlsoCoveredBy = nil
+			kept = append(kept, item)
+			continue
+		}
+
+		rep := &kept[idx]
+		if urlKey(item.URL) == urlKey(rep.URL) || item.Source == rep.Source {
+			continue
+		}
+		listed := false
+		for _, other := range rep.AlsoCoveredBy {
+			if other.Source == item.Source {
+				listed = true
+				break
+			}
+		}
+		if !listed {
+			rep.AlsoCoveredBy = append(rep.AlsoCoveredBy, Coverage{Title: item.Title, URL: item.URL, Source: item.Source})
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,135 @@
+package aggregator
+
+import "testing"
+
+// clusterBatch is a typical run's worth of titles from different sources,
+// so similarities are weighed against realistic term frequencies
+var clusterBatch = []RawNewsItem{
+	{Title: "OpenAI releases GPT-5", URL: "https://techcrunch.com/2024/06/10/openai-releases-gpt-5/", Source: "TechCrunch AI"},
+	{Title: "GPT-5 is here: OpenAI launches new model", URL: "https://www.theverge.com/2024/6/10/openai-gpt-5-launch", Source: "The Verge AI"},
+	{Title: "Anthropic releases Claude 4 with extended context", URL: "https://www.anthropic.com/news/claude-4", Source: "Anthropic Blog"},
+	{Title: "OpenAI releases Sora 2 video model to all users", URL: "https://openai.com/index/sora-2/", Source: "OpenAI Blog"},
+	{Title: "Google DeepMind unveils Gemini 2.5 Pro", URL: "https://blog.google/technology/ai/gemini-2-5-pro/", Source: "Google AI Blog"},
+	{Title: "Meta releases Llama 4 open weights", URL: "https://ai.meta.com/blog/llama-4/", Source: "Meta AI"},
+	{Title: "Nvidia reports record data center revenue", URL: "https://www.reuters.com/technology/nvidia-record-revenue/", Source: "Reuters"},
+	{Title: "Mistral raises $600M at a $6B valuation", URL: "https://techcrunch.com/2024/06/11/mistral-raises-600m/", Source: "TechCrunch AI"},
+	{Title: "EU AI Act enters into force", URL: "https://europa.eu/ai-act-in-force", Source: "EU News"},
+	{Title: "Senate hearing on AI safety draws tech CEOs", URL: "https://www.cnbc.com/2024/06/11/senate-ai-hearing.html", Source: "CNBC"},
+	{Title: "Scaling laws for sparse mixture-of-experts models", URL: "https://arxiv.org/abs/2406.01234", Source: "arXiv cs.LG"},
+	{Title: "A survey of retrieval-augmented generation", URL: "https://arxiv.org/abs/2406.05678", Source: "arXiv cs.CL"},
+	{Title: "Show HN: A tiny inference server for GGUF models", URL: "https://github.com/example/tiny-infer", Source: "Hacker News"},
+	{Title: "Why evals matter for agents", URL: "https://simonwillison.net/2024/Jun/10/evals/", Source: "Simon Willison Blog"},
+	{Title: "Hugging Face launches open leaderboard v2", URL: "https://huggingface.co/blog/leaderboard-v2", Source: "Hugging Face Blog"},
+	{Title: "Apple partners with OpenAI to bring ChatGPT to Siri", URL: "https://www.theverge.com/2024/6/10/apple-openai-siri", Source: "The Verge AI"},
+	{Title: "Microsoft Copilot gets GPT-4o upgrade", URL: "https://venturebeat.com/ai/copilot-gpt-4o/", Source: "VentureBeat AI"},
+	{Title: "Stability AI names new CEO", URL: "https://www.bloomberg.com/news/stability-ceo", Source: "Bloomberg"},
+	{Title: "New York Times lawsuit against OpenAI moves forward", URL: "https://www.reuters.com/legal/nyt-openai-lawsuit/", Source: "Reuters"},
+	{Title: "Karpathy: Let's build a tokenizer", URL: "https://karpathy.github.io/tokenizer/", Source: "Andrej Karpathy"},
+	{Title: "The bitter lesson, revisited", URL: "https://example.com/bitter-lesson", Source: "Some Blog"},
+	{Title: "Benchmarking long-context retrieval in open models", URL: "https://thegradient.pub/long-context/", Source: "The Gradient"},
+	{Title: "Ollama adds support for vision models", URL: "https://ollama.com/blog/vision", Source: "Ollama Blog"},
+	{Title: "Deepfake robocalls prompt FCC ruling", URL: "https://www.cnbc.com/2024/06/12/fcc-deepfake.html", Source: "CNBC"},
+}
+
+// clusterOf returns the cluster of each title in a clustering of items
+func clusterOf(items []RawNewsItem, threshold float64) map[string]int {
+	clusters := clusterItems(items, threshold)
+	byTitle := make(map[string]int, len(items))
+	for i, item := range items {
+		byTitle[item.Title] = clusters[i]
+	}
+	return byTitle
+}
+
+func TestClusterMergesSameStory(t *testing.T) {
+	clusters := clusterOf(clusterBatch, DefaultClusterThreshold)
+	if clusters["OpenAI releases GPT-5"] != clusters["GPT-5 is here: OpenAI launches new model"] {
+		t.Errorf("the two GPT-5 launch stories are in different clusters")
+	}
+
+	// Every other story stands alone
+	seen := make(map[int]string)
+	for _, item := range clusterBatch[2:] {
+		c := clusters[item.Title]
+		if other, ok := seen[c]; ok || c == clusters["OpenAI releases GPT-5"] {
+			t.Errorf("%q clustered with %q", item.Title, other)
+		}
+		seen[c] = item.Title
+	}
+}
+
+func TestClusterKeepsRelatedStoriesApart(t *testing.T) {
+	clusters := clusterOf(clusterBatch, DefaultClusterThreshold)
+	for _, pair := range [][2]string{
+		// Same company, same verb, different product
+		{"OpenAI releases GPT-5", "OpenAI releases Sora 2 video model to all users"},
+		// Same verb, different company and product
+		{"OpenAI releases GPT-5", "Anthropic releases Claude 4 with extended context"},
+		{"Meta releases Llama 4 open weights", "Anthropic releases Claude 4 with extended context"},
+	} {
+		if clusters[pair[0]] == clusters[pair[1]] {
+			t.Errorf("%q and %q share a cluster", pair[0], pair[1])
+		}
+	}
+}
+
+func TestClusterByURLAndTitle(t *testing.T) {
+	items := []RawNewsItem{
+		{Title: "Anthropic raises $4B from Amazon", URL: "https://techcrunch.com/anthropic-4b/", Source: "TechCrunch AI"},
+		{Title: "Amazon doubles down on Anthropic", URL: "https://www.techcrunch.com/anthropic-4b", Source: "Hacker News"},
+		{Title: "Anthropic Raises $4B From Amazon | TechCrunch", URL: "https://example.com/copy", Source: "Some Blog"},
+		{Title: "Unrelated story", URL: "https://example.com/other", Source: "Some Blog"},
+	}
+
+	// Threshold 0 turns off similarity, leaving only URL and title matches.
+	// URLs are canonicalised before clustering, so only the host prefix
+	// and trailing slash differ here.
+	clusters := clusterItems(items, 0)
+	if clusters[0] != clusters[1] {
+		t.Errorf("items with the same canonical URL are in different clusters")
+	}
+	if clusters[0] != clusters[2] {
+		t.Errorf("items with the same normalised title are in different clusters")
+	}
+	if clusters[3] == clusters[0] {
+		t.Errorf("unrelated item joined the cluster")
+	}
+}
+
+func TestClusterIgnoresEmptyTitles(t *testing.T) {
+	items := []RawNewsItem{
+		{URL: "https://example.com/a", Source: "Feed"},
+		{Title: "  ", URL: "https://example.com/b", Source: "Feed"},
+		{Title: "::", URL: "https://example.com/c", Source: "Other Feed"},
+		{URL: "https://www.example.com/a/", Source: "Other Feed"},
+	}
+
+	// Titles that normalise to nothing say nothing about the story; only
+	// the shared URL groups anything
+	clusters := clusterItems(items, 0)
+	if clusters[0] == clusters[1] || clusters[0] == clusters[2] || clusters[1] == clusters[2] {
+		t.Errorf("untitled items at different URLs clustered together: %v", clusters)
+	}
+	if clusters[0] != clusters[3] {
+		t.Errorf("untitled items at the same URL are in different clusters: %v", clusters)
+	}
+}
+
+func TestCollapseClusters(t *testing.T) {
+	items := []RawNewsItem{
+		{Title: "OpenAI releases GPT-5", URL: "https://techcrunch.com/gpt-5", Source: "TechCrunch AI", cluster: 0},
+		{Title: "Unrelated story", URL: "https://example.com/other", Source: "Some Blog", cluster: 1},
+		{Title: "GPT-5 is here", URL: "https://www.theverge.com/gpt-5", Source: "The Verge AI", cluster: 0},
+		{Title: "GPT-5 is here (updated)", URL: "https://www.theverge.com/gpt-5-update", Source: "The Verge AI", cluster: 0},
+		{Title: "OpenAI releases GPT-5", URL: "https://techcrunch.com/gpt-5/", Source: "Hacker News", cluster: 0},
+	}
+
+	kept := collapseClusters(items)
+	if len(kept) != 2 || kept[0].Title != "OpenAI releases GPT-5" || kept[1].Title != "Unrelated story" {
+		t.Fatalf("kept %v, want the first item of each cluster in order", kept)
+	}
+	want := []Coverage{{Title: "GPT-5 is here", URL: "https://www.theverge.com/gpt-5", Source: "The Verge AI"}}
+	if len(kept[0].AlsoCoveredBy) != 1 || kept[0].AlsoCoveredBy[0] != want[0] {
+		t.Errorf("AlsoCoveredBy = %v, want %v: one entry per source, none for the same URL", kept[0].AlsoCoveredBy, want)
+	}
+}
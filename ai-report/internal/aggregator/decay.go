@@ -0,0 +1,118 @@
+package aggregator
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DecayMode selects how an item's score falls off with age
+type DecayMode string
+
+const (
+	// DecayNone leaves scores untouched
+	DecayNone DecayMode = "none"
+
+	// DecayGravity divides the score by a power of the item's age, as Hacker
+	// News does: score * (2 / (ageHours + 2))^gravity
+	DecayGravity DecayMode = "gravity"
+
+	// DecayHalfLife halves the score every HalfLife
+	DecayHalfLife DecayMode = "halflife"
+)
+
+// futureSkew is how far in the future a publish date may lie and still be
+// put down to clock skew between us and the publisher
+const futureSkew = time.Hour
+
+// Decay discounts scores continuously by age. It runs after the scoring
+// pipeline, so a story loses rank gradually instead of dropping a bucket
+// the moment it turns one, six or 24 hours old.
+type Decay struct {
+	Mode     DecayMode
+	Gravity  float64
+	HalfLife time.Duration
+
+	// MissingAge is the age assumed for items without a usable publish
+	// date: those with none, and those dated more than an hour ahead. A
+	// date slightly in the future counts as brand new.
+	MissingAge time.Duration
+}
+
+// DefaultDecay halves scores every 12 hours, which suits a page rebuilt a
+// few times a day, and treats undated items as a day old. Gravity is set
+// to HN's 1.8 for when the mode is switched.
+func DefaultDecay() Decay {
+	return Decay{
+		Mode:       DecayHalfLife,
+		Gravity:    1.8,
+		HalfLife:   12 * time.Hour,
+		MissingAge: 24 * time.Hour,
+	}
+}
+
+// Validate reports settings Factor cannot work with
+func (d Decay) Validate() error {
+	switch d.Mode {
+	case DecayNone, "":
+	case DecayGravity:
+		if d.Gravity <= 0 {
+			return fmt.Errorf("gravity decay needs a positive gravity")
+		}
+	case DecayHalfLife:
+		if d.HalfLife <= 0 {
+			return fmt.Errorf("half-life decay needs a positive halfLife")
+		}
+	default:
+		return fmt.Errorf("unknown decay mode %q (want none, gravity or halflife)", d.Mode)
+	}
+	if d.MissingAge < 0 {
+		return fmt.Errorf("missingAge must not be negative")
+	}
+	return nil
+}
+
+// Age returns how old an item published at published is at now, applying
+// the rules for missing and future-dated timestamps
+func (d Decay) Age(published, now time.Time) time.Duration {
+	if published.IsZero() || published.Sub(now) > futureSkew {
+		return d.MissingAge
+	}
+	if published.After(now) {
+		return 0
+	}
+	return now.Sub(published)
+}
+
+// Factor returns the multiplier, between 0 and 1, for an item published at
+// published
+func (d Decay) Factor(published, now time.Time) float64 {
+	age := d.Age(published, now).Hours()
+
+	switch d.Mode {
+	case DecayGravity:
+		return math.Pow(2/(age+2), d.Gravity)
+	case DecayHalfLife:
+		return math.Pow(0.5, age/d.HalfLife.Hours())
+	default:
+		return 1
+	}
+}
+
+// Apply scales every item's score by its decay factor. The amount taken
+// off is recorded in the breakdown as "decay", so the breakdown still sums
+// to the score.
+func (d Decay) Apply(items []RawNewsItem, now time.Time) {
+	if d.Mode == DecayNone || d.Mode == "" {
+		return
+	}
+
+	for i := range items {
+		decayed := items[i].Score * d.Factor(items[i].PublishedAt, now)
+		if items[i].ScoreBreakdown == nil {
+			items[i].ScoreBreakdown = make(ScoreBreakdown)
+		}
+		items[i].ScoreBreakdown["decay"] = decayed - items[i].Score
+		items[i].Score = decayed
+	}
+}
@@ -0,0 +1,169 @@
+package aggregator
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+// rankedTitles ranks items with the given decay at now and returns their
+// titles best first
+func rankedTitles(decay Decay, items []RawNewsItem, now time.Time) []string {
+	a := New(WithDecay(decay))
+	var titles []string
+	for _, item := range a.rank(items, now) {
+		titles = append(titles, item.Title)
+	}
+	return titles
+}
+
+func hnStory(title string, points, comments int, published time.Time) RawNewsItem {
+	return RawNewsItem{
+		Title:       title,
+		PublishedAt: published,
+		Source:      "Hacker News",
+		Engagement:  Engagement{Platform: PlatformHackerNews, Points: points, Comments: comments},
+	}
+}
+
+func blogPost(title string, published time.Time) RawNewsItem {
+	return RawNewsItem{Title: title, PublishedAt: published, Source: "Some Blog"}
+}
+
+func TestDecayAge(t *testing.T) {
+	d := DefaultDecay()
+
+	tests := []struct {
+		name      string
+		published time.Time
+		want      time.Duration
+	}{
+		{"past", testNow.Add(-3 * time.Hour), 3 * time.Hour},
+		{"missing", time.Time{}, d.MissingAge},
+		{"clock skew", testNow.Add(10 * time.Minute), 0},
+		{"far future", testNow.Add(48 * time.Hour), d.MissingAge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Age(tt.published, testNow); got != tt.want {
+				t.Errorf("Age = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecayFactorIsContinuous(t *testing.T) {
+	for _, d := range []Decay{
+		{Mode: DecayGravity, Gravity: 1.8},
+		{Mode: DecayHalfLife, HalfLife: 12 * time.Hour},
+	} {
+		if f := d.Factor(testNow, testNow); f != 1 {
+			t.Errorf("%s: factor at age 0 = %v, want 1", d.Mode, f)
+		}
+
+		// No jump across the old bucket boundaries
+		for _, boundary := range []time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour} {
+			before := d.Factor(testNow.Add(-boundary+time.Minute), testNow)
+			after := d.Factor(testNow.Add(-boundary-time.Minute), testNow)
+			if after >= before || before-after > 0.02 {
+				t.Errorf("%s: factor jumps from %v to %v across %v", d.Mode, before, after, boundary)
+			}
+		}
+	}
+
+	half := Decay{Mode: DecayHalfLife, HalfLife: 12 * time.Hour}
+	if f := half.Factor(testNow.Add(-12*time.Hour), testNow); math.Abs(f-0.5) > 1e-9 {
+		t.Errorf("half-life factor after one half-life = %v, want 0.5", f)
+	}
+}
+
+func TestRankingOrderWithFixedClock(t *testing.T) {
+	items := func() []RawNewsItem {
+		return []RawNewsItem{
+			blogPost("OpenAI and Anthropic ship new LLM and GPT AI models", testNow.Add(-30*time.Minute)),
+			hnStory("Show HN: A tiny GPT in 200 lines", 900, 350, testNow.Add(-3*time.Hour)),
+			hnStory("Ask HN: Which LLM do you use?", 120, 200, testNow.Add(-20*time.Hour)),
+			blogPost("Undated LLM roundup", time.Time{}),
+			blogPost("LLM post from a fast clock", testNow.Add(10*time.Minute)),
+			blogPost("LLM post dated next week", testNow.Add(7*24*time.Hour)),
+		}
+	}
+
+	tests := []struct {
+		name  string
+		decay Decay
+		now   time.Time
+		want  []string
+	}{
+		{
+			name:  "half-life",
+			decay: DefaultDecay(),
+			now:   testNow,
+			want: []string{
+				"Show HN: A tiny GPT in 200 lines",
+				"OpenAI and Anthropic ship new LLM and GPT AI models",
+				"Ask HN: Which LLM do you use?",
+				"LLM post from a fast clock",
+				"Undated LLM roundup",
+				"LLM post dated next week",
+			},
+		},
+		{
+			// Half-life decay scales every dated item alike, so the order
+			// holds as the clock moves on
+			name:  "half-life twelve hours on",
+			decay: DefaultDecay(),
+			now:   testNow.Add(12 * time.Hour),
+			want: []string{
+				"Show HN: A tiny GPT in 200 lines",
+				"OpenAI and Anthropic ship new LLM and GPT AI models",
+				"Ask HN: Which LLM do you use?",
+				"LLM post from a fast clock",
+				"Undated LLM roundup",
+				"LLM post dated next week",
+			},
+		},
+		{
+			// Steep gravity favours fresh items over engagement
+			name:  "gravity",
+			decay: Decay{Mode: DecayGravity, Gravity: 1.8, MissingAge: 24 * time.Hour},
+			now:   testNow,
+			want: []string{
+				"OpenAI and Anthropic ship new LLM and GPT AI models",
+				"Show HN: A tiny GPT in 200 lines",
+				"LLM post from a fast clock",
+				"Ask HN: Which LLM do you use?",
+				"Undated LLM roundup",
+				"LLM post dated next week",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rankedTitles(tt.decay, items(), tt.now)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ranking =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(tt.want, "\n  "))
+			}
+		})
+	}
+}
+
+func TestDecayKeepsBreakdownConsistent(t *testing.T) {
+	items := []RawNewsItem{hnStory("Show HN: A tiny GPT in 200 lines", 900, 350, testNow.Add(-3*time.Hour))}
+	New().scoreItems(items, testNow)
+
+	sum := 0.0
+	for _, contribution := range items[0].ScoreBreakdown {
+		sum += contribution
+	}
+	if math.Abs(sum-items[0].Score) > 1e-9 {
+		t.Errorf("breakdown %v sums to %v, score is %v", items[0].ScoreBreakdown, sum, items[0].Score)
+	}
+	if items[0].ScoreBreakdown["decay"] >= 0 {
+		t.Errorf("decay contribution = %v, want negative", items[0].ScoreBreakdown["decay"])
+	}
+}
@@ -0,0 +1,95 @@
+package aggregator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// topSectionSize is the headline plus the top stories
+const topSectionSize = 4
+
+// Diversity limits how many items a single source or domain may place in
+// one section of the page, so a prolific feed cannot take over the top
+// stories or a column. The headline and top stories form one section with
+// their own limits; each column is a section with the other pair. Zero
+// means no limit.
+type Diversity struct {
+	TopPerSource int
+	TopPerDomain int
+	PerSource    int
+	PerDomain    int
+}
+
+// DefaultDiversity takes the headline and top stories from four different
+// sources and sites, and lets each column show at most two items from a
+// source and three from a site
+func DefaultDiversity() Diversity {
+	return Diversity{
+		TopPerSource: 1,
+		TopPerDomain: 1,
+		PerSource:    2,
+		PerDomain:    3,
+	}
+}
+
+// Validate reports negative limits
+func (d Diversity) Validate() error {
+	if d.TopPerSource < 0 || d.TopPerDomain < 0 || d.PerSource < 0 || d.PerDomain < 0 {
+		return fmt.Errorf("diversity limits must not be negative")
+	}
+	return nil
+}
+
+// sectionLimits counts the items a section has taken per source and domain
+type sectionLimits struct {
+	perSource int
+	perDomain int
+	sources   map[string]int
+	domains   map[string]int
+}
+
+func newSectionLimits(perSource, perDomain int) *sectionLimits {
+	return &sectionLimits{
+		perSource: perSource,
+		perDomain: perDomain,
+		sources:   make(map[string]int),
+		domains:   make(map[string]int),
+	}
+}
+
+// admit reports whether the section has room for another item from the
+// item's source and domain, counting it if so
+func (s *sectionLimits) admit(item RawNewsItem) bool {
+	domain := itemDomain(item.URL)
+	if s.perSource > 0 && s.sources[item.Source] >= s.perSource {
+		return false
+	}
+	if s.perDomain > 0 && domain != "" && s.domains[domain] >= s.perDomain {
+		return false
+	}
+	s.add(item)
+	return true
+}
+
+// add counts an item the section takes regardless of its limits
+func (s *sectionLimits) add(item RawNewsItem) {
+	s.sources[item.Source]++
+	if domain := itemDomain(item.URL); domain != "" {
+		s.domains[domain]++
+	}
+}
+
+// itemDomain returns the host of an item URL without "www." and similar
+// prefixes, or "" if the URL has none
+func itemDomain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m.", "mobile.", "amp."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	return host
+}
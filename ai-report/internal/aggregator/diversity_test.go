@@ -0,0 +1,121 @@
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/clock"
+)
+
+func sourcedStory(source, url, title string, points int) RawNewsItem {
+	item := hnStory(title, points, points/10, testNow.Add(-2*time.Hour))
+	item.Source = source
+	item.URL = url
+	return item
+}
+
+func TestProcessNewsDiversity(t *testing.T) {
+	// Firehose outranks everyone with nine stories across all three
+	// columns; two Wire sources share a site under different hostnames
+	items := []RawNewsItem{
+		sourcedStory("Firehose", "https://firehose.example.com/1", "Researchers release benchmark for protein folding", 900),
+		sourcedStory("Firehose", "https://firehose.example.com/2", "Chipmaker raises funding for datacenter expansion", 880),
+		sourcedStory("Firehose", "https://firehose.example.com/3", "Senate bill targets deepfake election ads", 860),
+		sourcedStory("Firehose", "https://firehose.example.com/4", "New paper on sparse attention kernels", 840),
+		sourcedStory("Firehose", "https://firehose.example.com/5", "Cloud provider launches cheaper inference tier", 820),
+		sourcedStory("Firehose", "https://firehose.example.com/6", "Court rules on copyright for training data", 800),
+		sourcedStory("Firehose", "https://firehose.example.com/7", "Study measures speech recognition accuracy across accents", 780),
+		sourcedStory("Firehose", "https://firehose.example.com/8", "Robotics startup announces warehouse customers", 760),
+		sourcedStory("Firehose", "https://firehose.example.com/9", "Regulators open privacy probe into assistant app", 740),
+		sourcedStory("Wire Feed", "https://www.wire.example.com/a", "Payments company acquires voice assistant maker", 450),
+		sourcedStory("Wire Feed", "https://m.wire.example.com/b", "Search engine pricing changes for enterprise plans", 420),
+		sourcedStory("Wire Reddit", "https://wire.example.com/c", "Translation startup valuation doubles after deal", 390),
+		sourcedStory("Wire Reddit", "https://amp.wire.example.com/d", "Semiconductor revenue beats forecasts this quarter", 360),
+		sourcedStory("Lab Blog", "https://lab.example.org/scans", "Dataset of annotated medical scans released for researchers", 300),
+		sourcedStory("Lab Blog", "https://lab.example.org/feedback", "Survey of reinforcement learning from feedback methods", 280),
+		sourcedStory("Policy Watch", "https://policy.example.net/act", "EU AI act enforcement timeline published", 260),
+		sourcedStory("Policy Watch", "https://policy.example.net/testing", "Lawmakers question safety testing of frontier systems", 240),
+		sourcedStory("Startup News", "https://startups.example.io/ipo", "Fintech IPO filing reveals machine learning spend", 220),
+	}
+	sources := make(map[string]string)
+	for _, item := range items {
+		sources[item.URL] = item.Source
+	}
+
+	d := DefaultDiversity()
+	p := New(WithClock(clock.Fixed(testNow)), WithDiversity(d)).ProcessNews(items)
+
+	// count tallies a section's items per source and per site
+	count := func(section []NewsItem) (map[string]int, map[string]int) {
+		bySource, byDomain := make(map[string]int), make(map[string]int)
+		for _, item := range section {
+			bySource[sources[item.URL]]++
+			byDomain[itemDomain(item.URL)]++
+		}
+		return bySource, byDomain
+	}
+
+	top := append([]NewsItem{p.TopStory}, p.TopStories...)
+	if len(top) != topSectionSize {
+		t.Fatalf("headline and top stories hold %d items, want %d", len(top), topSectionSize)
+	}
+	if sources[p.TopStory.URL] != "Firehose" {
+		t.Errorf("headline from %q, want the best ranked source", sources[p.TopStory.URL])
+	}
+	bySource, byDomain := count(top)
+	if len(bySource) != topSectionSize || len(byDomain) != topSectionSize {
+		t.Errorf("top section sources %v, sites %v, want %d different of each", bySource, byDomain, topSectionSize)
+	}
+
+	columns := []struct {
+		name  string
+		items []NewsItem
+	}{{"left", p.LeftColumn}, {"center", p.CenterColumn}, {"right", p.RightColumn}}
+	for _, column := range columns {
+		bySource, byDomain := count(column.items)
+		for source, n := range bySource {
+			if n > d.PerSource {
+				t.Errorf("%s column has %d items from %s, want at most %d", column.name, n, source, d.PerSource)
+			}
+		}
+		for domain, n := range byDomain {
+			if n > d.PerDomain {
+				t.Errorf("%s column has %d items from %s, want at most %d", column.name, n, domain, d.PerDomain)
+			}
+		}
+
+		// Firehose has more left over for every column than its limit
+		if bySource["Firehose"] != d.PerSource {
+			t.Errorf("%s column has %d Firehose items, want the limit of %d", column.name, bySource["Firehose"], d.PerSource)
+		}
+	}
+
+	// The Wire sources are within their own limits, but not the site's
+	if _, byDomain := count(p.CenterColumn); byDomain["wire.example.com"] != d.PerDomain {
+		t.Errorf("center column has %d items from wire.example.com, want the limit of %d", byDomain["wire.example.com"], d.PerDomain)
+	}
+}
+
+func TestItemDomain(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.example.com/post", "example.com"},
+		{"https://m.example.com/post", "example.com"},
+		{"https://mobile.example.com/post", "example.com"},
+		{"https://amp.example.com/post", "example.com"},
+		{"https://WWW.Example.COM:8080/post", "example.com"},
+		{"https://blog.example.com/post", "blog.example.com"},
+		{"https://www.m.example.com/post", "example.com"},
+		{"https://mwww.example.com/post", "mwww.example.com"},
+		{"/relative/path", ""},
+		{"://bad", ""},
+	}
+
+	for _, tt := range tests {
+		if got := itemDomain(tt.url); got != tt.want {
+			t.Errorf("itemDomain(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
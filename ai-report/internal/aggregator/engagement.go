@@ -0,0 +1,82 @@
+package aggregator
+
+import "math"
+
+// Platform identifies where an item's engagement metrics come from
+type Platform string
+
+const (
+	PlatformHackerNews Platform = "hackernews"
+	PlatformReddit     Platform = "reddit"
+	PlatformTwitter    Platform = "twitter"
+)
+
+// Engagement holds the audience signals a platform reports for an item.
+// Zero values mean the platform did not report that signal.
+type Engagement struct {
+	Platform Platform
+
+	Points   int // HN points, Reddit score
+	Comments int
+
+	// UpvoteRatio is the share of votes that were upvotes, between 0 and 1
+	UpvoteRatio float64
+
+	// Shares counts reposts, such as retweets
+	Shares int
+}
+
+// engagementNorm is the level of each signal at which an item counts as
+// highly engaged on its platform. Platforms differ by orders of magnitude
+// (a 500-point HN story is rarer than a 5,000-point Reddit post), so raw
+// counts are only compared after dividing by these.
+type engagementNorm struct {
+	points   float64
+	comments float64
+	shares   float64
+}
+
+var engagementNorms = map[Platform]engagementNorm{
+	PlatformHackerNews: {points: 500, comments: 300},
+	PlatformReddit:     {points: 5000, comments: 1000},
+	PlatformTwitter:    {points: 5000, comments: 500, shares: 1000},
+}
+
+const (
+	// engagementWeight is the score an item at its platform's norm earns
+	engagementWeight = 20.0
+
+	// maxEngagement caps the normalised signal so one viral item cannot
+	// drown out every other consideration
+	maxEngagement = 1.5
+)
+
+// normalizedEngagement maps an item's metrics onto a 0..maxEngagement scale
+// that is comparable across platforms. Counts are log-scaled against the
+// platform's norm, so the first hundred points matter more than the next
+// thousand. Items without engagement data, such as blog posts, score 0.
+func normalizedEngagement(e Engagement) float64 {
+	norm, ok := engagementNorms[e.Platform]
+	if !ok {
+		return 0
+	}
+
+	scaled := func(count int, ref float64) float64 {
+		if count <= 0 || ref <= 0 {
+			return 0
+		}
+		return math.Log1p(float64(count)) / math.Log1p(ref)
+	}
+
+	signal := 0.7*scaled(e.Points, norm.points) + 0.3*scaled(e.Comments, norm.comments)
+	if norm.shares > 0 {
+		signal += 0.3 * scaled(e.Shares, norm.shares)
+	}
+
+	// A divisive post is discounted by how contested its votes were
+	if e.UpvoteRatio > 0 && e.UpvoteRatio < 1 {
+		signal *= e.UpvoteRatio
+	}
+
+	return math.Min(signal, maxEngagement)
+}
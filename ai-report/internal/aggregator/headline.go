@@ -0,0 +1,187 @@
+package aggregator
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// siteSeparators join a headline to the name of the site that published
+// it, as in "Title | TechCrunch"
+var siteSeparators = []string{" | ", " - ", " – ", " — ", " · ", " :: "}
+
+// sourceNameSuffixes are words source names carry that sites leave out of
+// their titles: the "AI" feed of The Verge still signs its titles "The Verge"
+var sourceNameSuffixes = []string{" AI", " Blog", " News"}
+
+// minSiteName is the shortest site name trimmed from headlines, so a short
+// stem like "MIT" is not taken for a signature
+const minSiteName = 4
+
+// htmlTag matches the inline markup some feeds leave in titles. Only
+// known tags are matched, so "Vec<T>" survives.
+var htmlTag = regexp.MustCompile(`(?i)</?(a|b|br|code|em|i|mark|p|small|span|strong|sub|sup|u)(\s[^<>]*)?/?>`)
+
+// Headlines turns raw titles into display headlines. Cleaning decodes
+// entities, drops stray markup and invisible characters, collapses
+// whitespace, trims a trailing site name and applies the rewrite rules in
+// order. Emphasis then upper-cases headlines in the Drudge style.
+type Headlines struct {
+	// SiteNames are trimmed when they end a title after a separator such
+	// as " | ". The names of the run's sources are always added.
+	SiteNames []string
+	Rewrites  []Rewrite
+	Emphasis  Emphasis
+}
+
+// Rewrite replaces matches of Pattern with Replace, which may refer to
+// submatches as $1
+type Rewrite struct {
+	Pattern *regexp.Regexp
+	Replace string
+}
+
+// Emphasis decides which headlines are set in capitals: any containing
+// one of Always, and those shorter than MaxLength characters that mention
+// one of Topics. Words match whole and regardless of case.
+type Emphasis struct {
+	Always    []string
+	Topics    []string
+	MaxLength int
+}
+
+// DefaultHeadlines trims the signatures of the big tech sites and keeps
+// the original emphasis rules
+func DefaultHeadlines() Headlines {
+	return Headlines{
+		SiteNames: []string{
+			"TechCrunch", "The Verge", "Ars Technica", "Wired", "VentureBeat",
+			"Reuters", "Bloomberg", "CNBC", "Axios", "Engadget", "ZDNet",
+			"The Information", "Financial Times", "The New York Times",
+			"MIT Technology Review", "Hacker News",
+		},
+		Emphasis: Emphasis{
+			Always:    []string{"BREAKING", "EXCLUSIVE", "URGENT"},
+			Topics:    []string{"GPT-5", "GPT5", "CHATGPT", "OPENAI", "GOOGLE", "MICROSOFT", "META", "APPLE"},
+			MaxLength: 60,
+		},
+	}
+}
+
+// Validate reports settings Emphasize cannot work with
+func (h Headlines) Validate() error {
+	if h.Emphasis.MaxLength < 0 {
+		return fmt.Errorf("emphasis maxLength must not be negative")
+	}
+	for i, r := range h.Rewrites {
+		if r.Pattern == nil {
+			return fmt.Errorf("rewrite %d has no pattern", i+1)
+		}
+	}
+	return nil
+}
+
+// siteNames returns the configured site names together with the names of
+// the sources in items and their stems, longest first so "The Verge AI"
+// is tried before "The Verge"
+func (h Headlines) siteNames(items []RawNewsItem) []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if utf8.RuneCountInString(key) < minSiteName || seen[key] {
+			return
+		}
+		seen[key] = true
+		names = append(names, key)
+	}
+
+	for _, name := range h.SiteNames {
+		add(name)
+	}
+	for _, item := range items {
+		name := item.Source
+		add(name)
+		for trimmed := true; trimmed; {
+			trimmed = false
+			for _, suffix := range sourceNameSuffixes {
+				if strings.HasSuffix(name, suffix) {
+					name = strings.TrimSuffix(name, suffix)
+					add(name)
+					trimmed = true
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	return names
+}
+
+// Clean returns title tidied for display. siteNames are the signatures to
+// trim, longest first, as from siteNames.
+func (h Headlines) Clean(title string, siteNames []string) string {
+	// Feeds sometimes encode twice, as in "&amp;#8217;"
+	for i := 0; i < 2; i++ {
+		decoded := html.UnescapeString(title)
+		if decoded == title {
+			break
+		}
+		title = decoded
+	}
+	title = htmlTag.ReplaceAllString(title, "")
+	title = collapseSpace(title)
+
+trim:
+	for _, name := range siteNames {
+		for _, sep := range siteSeparators {
+			n := len(sep) + len(name)
+			if len(title) > n && strings.EqualFold(title[len(title)-n:], sep+name) {
+				title = strings.TrimSpace(title[:len(title)-n])
+				break trim
+			}
+		}
+	}
+
+	for _, r := range h.Rewrites {
+		title = r.Pattern.ReplaceAllString(title, r.Replace)
+	}
+	return collapseSpace(title)
+}
+
+// collapseSpace drops zero-width characters and reduces runs of
+// whitespace, including non-breaking spaces and newlines, to one space
+func collapseSpace(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '\u200b', '\u200c', '\u200d', '\ufeff':
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Emphasize returns the headline in capitals if the emphasis rules call
+// for it, and unchanged otherwise
+func (h Headlines) Emphasize(title string) string {
+	text := matchText(title)
+	for _, word := range h.Emphasis.Always {
+		if strings.Contains(text, matchText(word)) {
+			return strings.ToUpper(title)
+		}
+	}
+
+	if utf8.RuneCountInString(title) >= h.Emphasis.MaxLength {
+		return title
+	}
+	for _, topic := range h.Emphasis.Topics {
+		if strings.Contains(text, matchText(topic)) {
+			return strings.ToUpper(title)
+		}
+	}
+	return title
+}
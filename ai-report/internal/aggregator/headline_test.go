@@ -0,0 +1,97 @@
+package aggregator
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// testHeadlines are the default rules with the rewrites from the shipped
+// config
+func testHeadlines() Headlines {
+	h := DefaultHeadlines()
+	h.Rewrites = []Rewrite{
+		{Pattern: regexp.MustCompile(`\s*\[(?i:pdf|video)\]$`)},
+		{Pattern: regexp.MustCompile(`\s*\((19|20)\d\d\)$`)},
+	}
+	return h
+}
+
+// readTitles parses testdata/headlines/titles.txt into items
+func readTitles(t *testing.T) []RawNewsItem {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "headlines", "titles.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var items []RawNewsItem
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		source, quoted, ok := strings.Cut(text, "\t")
+		if !ok {
+			t.Fatalf("titles.txt:%d: want source<TAB>title", line)
+		}
+		title, err := strconv.Unquote(quoted)
+		if err != nil {
+			t.Fatalf("titles.txt:%d: %v", line, err)
+		}
+		items = append(items, RawNewsItem{Title: title, Source: source})
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+func TestHeadlinesGolden(t *testing.T) {
+	items := readTitles(t)
+	h := testHeadlines()
+	sites := h.siteNames(items)
+
+	var b strings.Builder
+	for _, item := range items {
+		fmt.Fprintf(&b, "%q\n=> %q\n\n", item.Title, h.Emphasize(h.Clean(item.Title, sites)))
+	}
+	got := b.String()
+
+	golden := filepath.Join("testdata", "headlines", "titles.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("headlines differ from %s; rerun with -update and review the diff:\n%s", golden, got)
+	}
+}
+
+func TestCleanIsIdempotent(t *testing.T) {
+	items := readTitles(t)
+	h := testHeadlines()
+	sites := h.siteNames(items)
+
+	for _, item := range items {
+		once := h.Clean(item.Title, sites)
+		if twice := h.Clean(once, sites); twice != once {
+			t.Errorf("Clean(%q) = %q, but cleaning again gives %q", item.Title, once, twice)
+		}
+	}
+}
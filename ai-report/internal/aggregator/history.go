@@ -0,0 +1,71 @@
+package aggregator
+
+import (
+	"log"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/store"
+)
+
+// recall sets each item's FirstSeen from the store, or to now for items
+// it has not seen, and dates items whose own date is missing or not
+// believable by when they were first seen
+func (a *Aggregator) recall(items []RawNewsItem, now time.Time) {
+	if a.store == nil {
+		return
+	}
+
+	urls := make([]string, len(items))
+	for i, item := range items {
+		urls[i] = item.URL
+	}
+	records, err := a.store.Lookup(urls)
+	if err != nil {
+		log.Printf("Warning: Failed to read item store: %v", err)
+		return
+	}
+
+	redated := 0
+	for i := range items {
+		items[i].FirstSeen = now
+		// A replay may run against a store that has since seen the item
+		// again; it can only have been first seen by then
+		if rec, ok := records[items[i].URL]; ok && rec.FirstSeen.Before(now) {
+			items[i].FirstSeen = rec.FirstSeen
+		}
+
+		if dateSuspect(items[i], now) {
+			items[i].PublishedAt = items[i].FirstSeen
+			redated++
+		}
+	}
+	if redated > 0 {
+		log.Printf("Dated %d items by when they were first seen", redated)
+	}
+}
+
+// dateSuspect reports whether an item's publish date should give way to
+// when it was first seen: when it has none, when it lies in the future, or
+// when it is later than the item was first seen, as for feeds that stamp
+// every item with the time of the request
+func dateSuspect(item RawNewsItem, now time.Time) bool {
+	published := item.PublishedAt
+	return published.IsZero() ||
+		published.Sub(now) > futureSkew ||
+		published.Sub(item.FirstSeen) > futureSkew
+}
+
+// remember records the run's scored items in the store
+func (a *Aggregator) remember(items []RawNewsItem, now time.Time) {
+	if a.store == nil {
+		return
+	}
+
+	sightings := make([]store.Sighting, len(items))
+	for i, item := range items {
+		sightings[i] = store.Sighting{URL: item.URL, Title: item.Title, Source: item.Source, Score: item.Score}
+	}
+	if err := a.store.Record(sightings, now); err != nil {
+		log.Printf("Warning: Failed to update item store: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package aggregator
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/clock"
+	"github.com/ai-report/aggregator/internal/store"
+)
+
+// runWithStore processes items at now against the store at path, as one
+// run would, and returns the ranked items
+func runWithStore(t *testing.T, path string, readOnly bool, items []RawNewsItem, now time.Time) []RawNewsItem {
+	t.Helper()
+	open := store.Open
+	if readOnly {
+		open = store.OpenReadOnly
+	}
+	s, err := open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	return New(WithClock(clock.Fixed(now)), WithStore(s)).ProcessNews(items).Ranked
+}
+
+func TestRecallAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.db")
+	later := testNow.Add(3 * time.Hour)
+
+	undated := func() RawNewsItem {
+		return RawNewsItem{Title: "Undated post", URL: "https://example.com/undated", Source: "Some Blog"}
+	}
+
+	first := runWithStore(t, path, false, []RawNewsItem{undated()}, testNow)
+	if !first[0].FirstSeen.Equal(testNow) || !first[0].PublishedAt.Equal(testNow) {
+		t.Errorf("first run: FirstSeen %v, PublishedAt %v, want both now", first[0].FirstSeen, first[0].PublishedAt)
+	}
+
+	// The next run keeps the first sighting, so the undated post ages
+	second := runWithStore(t, path, false, []RawNewsItem{undated()}, later)
+	if !second[0].FirstSeen.Equal(testNow) || !second[0].PublishedAt.Equal(testNow) {
+		t.Errorf("second run: FirstSeen %v, PublishedAt %v, want the first run's %v", second[0].FirstSeen, second[0].PublishedAt, testNow)
+	}
+
+	// A read-only run sees the history but adds nothing to it
+	dated := blogPost("Dated post about something else", testNow.Add(-2*time.Hour))
+	dated.URL = "https://example.com/dated"
+	runWithStore(t, path, true, []RawNewsItem{undated(), dated}, later.Add(time.Hour))
+
+	s, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	records, err := s.Lookup([]string{"https://example.com/undated", "https://example.com/dated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Errorf("store holds %d of the items, want only the one recorded by the first two runs", len(records))
+	}
+	if rec := records["https://example.com/undated"]; !rec.FirstSeen.Equal(testNow) || !rec.LastSeen.Equal(later) {
+		t.Errorf("record first seen %v, last seen %v, want %v and %v", rec.FirstSeen, rec.LastSeen, testNow, later)
+	}
+}
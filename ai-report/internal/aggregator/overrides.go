@@ -0,0 +1,235 @@
+package aggregator
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Slot is the part of the page a pinned story is held in
+type Slot string
+
+const (
+	SlotHeadline Slot = "headline"
+	SlotTop      Slot = "top"
+	SlotLeft     Slot = "left"
+	SlotCenter   Slot = "center"
+	SlotRight    Slot = "right"
+)
+
+// ParseSlot returns the slot with the given name
+func ParseSlot(name string) (Slot, error) {
+	switch slot := Slot(name); slot {
+	case SlotHeadline, SlotTop, SlotLeft, SlotCenter, SlotRight:
+		return slot, nil
+	}
+	return "", fmt.Errorf("unknown slot %q (want headline, top, left, center or right)", name)
+}
+
+// Overrides are editorial decisions applied on top of the ranking. Each
+// entry may expire; a zero Expires never does. Every override applied is
+// logged along with its note.
+type Overrides struct {
+	Pins   []Pin
+	Boosts []Boost
+	Bans   []Ban
+}
+
+// Pin holds the story at URL in a slot, ahead of the ranked items there
+// and regardless of diversity limits. Title is used if the story is not
+// among the run's items, so a pin can keep a story up after it has left
+// the feeds; without one the pin waits for the story to appear.
+type Pin struct {
+	URL     string
+	Slot    Slot
+	Title   string
+	Expires time.Time
+	Note    string
+}
+
+// Boost adds Delta to the score of the item at URL, or of every item with
+// Keyword in its title. A negative Delta buries items.
+type Boost struct {
+	URL     string
+	Keyword string
+	Delta   float64
+	Expires time.Time
+	Note    string
+}
+
+// Ban drops items before they are clustered or ranked: the item at URL,
+// every item from Domain or its subdomains, or every item whose title
+// matches Title. Exactly one of them is set.
+type Ban struct {
+	URL     string
+	Domain  string
+	Title   *regexp.Regexp
+	Expires time.Time
+	Note    string
+}
+
+// activeAt reports whether an override expiring at expires applies at now,
+// logging it once it has lapsed so stale entries are noticed
+func activeAt(kind, target string, expires, now time.Time) bool {
+	if expires.IsZero() || now.Before(expires) {
+		return true
+	}
+	log.Printf("Override: %s %s expired at %s, ignoring", kind, target, expires.Format(time.RFC3339))
+	return false
+}
+
+// sameURL reports whether an item URL and a URL from the overrides file
+// point at the same page
+func sameURL(itemURL, overrideURL string) bool {
+	return urlKey(itemURL) == urlKey(CanonicalURL(overrideURL))
+}
+
+// withNote appends an override's note to a log message
+func withNote(msg, note string) string {
+	if note == "" {
+		return msg
+	}
+	return msg + " (" + note + ")"
+}
+
+// target describes what a ban matches, for logs
+func (b Ban) target() string {
+	switch {
+	case b.URL != "":
+		return "url " + b.URL
+	case b.Domain != "":
+		return "domain " + b.Domain
+	case b.Title != nil:
+		return "title /" + b.Title.String() + "/"
+	}
+	return "nothing"
+}
+
+// matches reports whether the ban applies to item
+func (b Ban) matches(item RawNewsItem) bool {
+	switch {
+	case b.URL != "":
+		return sameURL(item.URL, b.URL)
+	case b.Domain != "":
+		domain := strings.ToLower(strings.TrimPrefix(b.Domain, "www."))
+		host := itemDomain(item.URL)
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	case b.Title != nil:
+		return b.Title.MatchString(item.Title)
+	}
+	return false
+}
+
+// ban returns items without those an active ban matches
+func (o Overrides) ban(items []RawNewsItem, now time.Time) []RawNewsItem {
+	var bans []Ban
+	for _, b := range o.Bans {
+		if activeAt("ban", b.target(), b.Expires, now) {
+			bans = append(bans, b)
+		}
+	}
+	if len(bans) == 0 {
+		return items
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		banned := false
+		for _, b := range bans {
+			if b.matches(item) {
+				log.Print(withNote(fmt.Sprintf("Override: banned %q (%s) by %s", item.Title, item.Source, b.target()), b.Note))
+				banned = true
+				break
+			}
+		}
+		if !banned {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// boost adds each active boost's delta to the items it matches, recording
+// it in the breakdown as "override"
+func (o Overrides) boost(items []RawNewsItem, now time.Time) {
+	for _, b := range o.Boosts {
+		target := "url " + b.URL
+		if b.URL == "" {
+			target = "keyword " + b.Keyword
+		}
+		if !activeAt("boost", target, b.Expires, now) {
+			continue
+		}
+
+		keyword := matchText(b.Keyword)
+		for i := range items {
+			if b.URL != "" && !sameURL(items[i].URL, b.URL) {
+				continue
+			}
+			if b.URL == "" && !strings.Contains(matchText(items[i].Title), keyword) {
+				continue
+			}
+
+			items[i].Score += b.Delta
+			if items[i].ScoreBreakdown == nil {
+				items[i].ScoreBreakdown = make(ScoreBreakdown)
+			}
+			items[i].ScoreBreakdown["override"] += b.Delta
+			log.Print(withNote(fmt.Sprintf("Override: boosted %q (%s) by %+g for %s", items[i].Title, items[i].Source, b.Delta, target), b.Note))
+		}
+	}
+}
+
+// pin takes the items of active pins out of the ranked list and returns
+// them by slot, along with the items left to lay out. A story pinned twice
+// keeps its first pin.
+func (o Overrides) pin(items []RawNewsItem, now time.Time, categorize func(RawNewsItem) Category) (map[Slot][]RawNewsItem, []RawNewsItem) {
+	pinned := make(map[Slot][]RawNewsItem)
+	taken := make(map[int]bool)
+
+	for _, p := range o.Pins {
+		if !activeAt("pin", p.URL, p.Expires, now) {
+			continue
+		}
+
+		found := -1
+		for i, item := range items {
+			if sameURL(item.URL, p.URL) {
+				found = i
+				break
+			}
+		}
+
+		var item RawNewsItem
+		switch {
+		case found >= 0 && taken[found]:
+			log.Printf("Override: %s is already pinned, ignoring its %s pin", p.URL, p.Slot)
+			continue
+		case found >= 0:
+			taken[found] = true
+			item = items[found]
+		case p.Title != "":
+			item = RawNewsItem{Title: p.Title, URL: CanonicalURL(p.URL), Source: "Editors"}
+			item.Category = categorize(item)
+		default:
+			log.Printf("Override: pinned %s is not in this run and has no title, skipping", p.URL)
+			continue
+		}
+
+		pinned[p.Slot] = append(pinned[p.Slot], item)
+		log.Print(withNote(fmt.Sprintf("Override: pinned %q to %s", item.Title, p.Slot), p.Note))
+	}
+
+	if len(taken) == 0 {
+		return pinned, items
+	}
+	rest := make([]RawNewsItem, 0, len(items)-len(taken))
+	for i, item := range items {
+		if !taken[i] {
+			rest = append(rest, item)
+		}
+	}
+	return pinned, rest
+}
@@ -0,0 +1,186 @@
+package aggregator
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/clock"
+)
+
+func linkedStory(title, url string, points int) RawNewsItem {
+	item := hnStory(title, points, points/10, testNow.Add(-time.Hour))
+	item.URL = url
+	return item
+}
+
+// overrideBatch is a run of unrelated stories, best first by engagement
+func overrideBatch() []RawNewsItem {
+	return []RawNewsItem{
+		linkedStory("Chip maker unveils faster accelerator", "https://chips.example.com/accelerator", 400),
+		linkedStory("Lab publishes open weights model", "https://lab.example.com/open-weights", 200),
+		linkedStory("Startup raises funding for robotics", "https://www.robots.example.com/funding/?utm_source=hn", 50),
+	}
+}
+
+// sectionURLs lists the URLs shown in each part of the page
+func sectionURLs(p *ProcessedNews) map[string][]string {
+	sections := map[string][]NewsItem{
+		"headline": {p.TopStory},
+		"top":      p.TopStories,
+		"left":     p.LeftColumn,
+		"center":   p.CenterColumn,
+		"right":    p.RightColumn,
+	}
+	urls := make(map[string][]string)
+	for name, items := range sections {
+		for _, item := range items {
+			if item.URL != "" {
+				urls[name] = append(urls[name], item.URL)
+			}
+		}
+	}
+	return urls
+}
+
+func processWith(o Overrides, items []RawNewsItem) *ProcessedNews {
+	return New(WithClock(clock.Fixed(testNow)), WithOverrides(o)).ProcessNews(items)
+}
+
+func TestPinStoryNotInRun(t *testing.T) {
+	o := Overrides{Pins: []Pin{
+		{URL: "https://editors.example.com/kept-up?utm_source=x", Slot: SlotHeadline, Title: "Editors keep the story up"},
+		{URL: "https://editors.example.com/untitled", Slot: SlotTop},
+	}}
+
+	pinned, rest := o.pin(overrideBatch(), testNow, DefaultCategorizer().Categorize)
+	if len(rest) != 3 {
+		t.Errorf("pin left %d items, want all 3", len(rest))
+	}
+	if len(pinned[SlotTop]) != 0 {
+		t.Errorf("untitled pin for a story not in the run was kept: %+v", pinned[SlotTop])
+	}
+	got := pinned[SlotHeadline]
+	if len(got) != 1 || got[0].Source != "Editors" || got[0].URL != "https://editors.example.com/kept-up" || got[0].Title != "Editors keep the story up" {
+		t.Fatalf("headline pins = %+v, want the synthetic Editors item", got)
+	}
+
+	p := processWith(o, overrideBatch())
+	if p.TopStory.URL != "https://editors.example.com/kept-up" {
+		t.Errorf("headline = %q, want the pinned story", p.TopStory.URL)
+	}
+	if len(p.TopStories) == 0 || p.TopStories[0].URL != "https://chips.example.com/accelerator" {
+		t.Errorf("top stories = %+v, want the best ranked story first", p.TopStories)
+	}
+}
+
+func TestPinStoryInRun(t *testing.T) {
+	o := Overrides{Pins: []Pin{
+		{URL: "https://lab.example.com/open-weights/", Slot: SlotRight},
+		{URL: "https://lab.example.com/open-weights", Slot: SlotHeadline},
+	}}
+	p := processWith(o, overrideBatch())
+
+	urls := sectionURLs(p)
+	if got := urls["right"]; len(got) == 0 || got[0] != "https://lab.example.com/open-weights" {
+		t.Errorf("right column = %v, want the pinned story first", got)
+	}
+	for name, list := range urls {
+		for i, url := range list {
+			if url == "https://lab.example.com/open-weights" && !(name == "right" && i == 0) {
+				t.Errorf("pinned story also shown in %s", name)
+			}
+		}
+	}
+
+	// The second pin of the same story is ignored, so the best ranked
+	// story keeps the headline
+	if p.TopStory.URL != "https://chips.example.com/accelerator" {
+		t.Errorf("headline = %q, want the best ranked story", p.TopStory.URL)
+	}
+}
+
+func TestBoostReordersRanking(t *testing.T) {
+	a := New()
+	ranked := a.rank(overrideBatch(), testNow)
+	gap := ranked[0].Score - ranked[2].Score
+
+	delta := gap + 1
+	a = New(WithOverrides(Overrides{Boosts: []Boost{
+		{Keyword: "ROBOTICS", Delta: delta},
+		{URL: "https://lab.example.com/open-weights", Delta: -gap - 1},
+	}}))
+	var titles []string
+	for _, item := range a.rank(overrideBatch(), testNow) {
+		titles = append(titles, item.Title)
+		switch item.Title {
+		case "Startup raises funding for robotics":
+			if item.ScoreBreakdown["override"] != delta {
+				t.Errorf("boosted item breakdown = %v, want override %+g", item.ScoreBreakdown, delta)
+			}
+		case "Chip maker unveils faster accelerator":
+			if _, ok := item.ScoreBreakdown["override"]; ok {
+				t.Errorf("unboosted item has an override in its breakdown: %v", item.ScoreBreakdown)
+			}
+		}
+	}
+
+	want := "Startup raises funding for robotics,Chip maker unveils faster accelerator,Lab publishes open weights model"
+	if got := strings.Join(titles, ","); got != want {
+		t.Errorf("ranked %s\nwant %s", got, want)
+	}
+}
+
+func TestExpiredOverridesIgnored(t *testing.T) {
+	for _, expires := range []time.Time{testNow.Add(-time.Hour), testNow} {
+		o := Overrides{
+			Pins:   []Pin{{URL: "https://lab.example.com/open-weights", Slot: SlotHeadline, Expires: expires}},
+			Boosts: []Boost{{Keyword: "robotics", Delta: 100, Expires: expires}},
+			Bans:   []Ban{{Domain: "chips.example.com", Expires: expires}},
+		}
+
+		items := o.ban(overrideBatch(), testNow)
+		if len(items) != 3 {
+			t.Errorf("ban expiring at %v dropped items: %d left", expires, len(items))
+		}
+		o.boost(items, testNow)
+		for _, item := range items {
+			if _, ok := item.ScoreBreakdown["override"]; ok {
+				t.Errorf("boost expiring at %v applied to %q", expires, item.Title)
+			}
+		}
+		if pinned, _ := o.pin(items, testNow, DefaultCategorizer().Categorize); len(pinned) != 0 {
+			t.Errorf("pin expiring at %v applied: %+v", expires, pinned)
+		}
+	}
+
+	// One that has not expired yet still applies
+	o := Overrides{Bans: []Ban{{Domain: "chips.example.com", Expires: testNow.Add(time.Minute)}}}
+	if items := o.ban(overrideBatch(), testNow); len(items) != 2 {
+		t.Errorf("unexpired ban left %d items, want 2", len(items))
+	}
+}
+
+func TestBanAfterCanonicalisation(t *testing.T) {
+	items := append(overrideBatch(),
+		linkedStory("Spam site reposts the news", "https://news.spam.example.org/repost", 300),
+		linkedStory("Neighbouring site reports on compilers", "https://notspam.example.org/compilers", 20),
+		linkedStory("Sponsored: buy our GPU cloud", "https://cloud.example.net/offer", 250),
+	)
+	o := Overrides{Bans: []Ban{
+		{URL: "http://robots.example.com/funding"},
+		{Domain: "www.Spam.example.org"},
+		{Title: regexp.MustCompile(`^Sponsored:`)},
+	}}
+	p := processWith(o, items)
+
+	var urls []string
+	for _, item := range p.Ranked {
+		urls = append(urls, item.URL)
+	}
+	want := []string{"https://chips.example.com/accelerator", "https://lab.example.com/open-weights", "https://notspam.example.org/compilers"}
+	if strings.Join(urls, " ") != strings.Join(want, " ") {
+		t.Errorf("ranked %v, want %v", urls, want)
+	}
+}
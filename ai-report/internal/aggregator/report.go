@@ -0,0 +1,183 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// FetchStatus is the outcome of fetching a single source
+type FetchStatus string
+
+const (
+	StatusOK      FetchStatus = "ok"
+	StatusError   FetchStatus = "error"
+	StatusTimeout FetchStatus = "timeout"
+	StatusSkipped FetchStatus = "skipped"
+)
+
+// ErrorClass groups fetch errors by their cause
+type ErrorClass string
+
+const (
+	ErrorClassTimeout    ErrorClass = "timeout"
+	ErrorClassHTTPStatus ErrorClass = "http_status"
+	ErrorClassParse      ErrorClass = "parse"
+	ErrorClassNetwork    ErrorClass = "network"
+	ErrorClassOther      ErrorClass = "other"
+)
+
+// FetchReport summarises a FetchAll run
+type FetchReport struct {
+	StartedAt  time.Time      `json:"startedAt"`
+	DurationMS int64          `json:"durationMs"`
+	OK         int            `json:"ok"`
+	Failed     int            `json:"failed"`
+	TimedOut   int            `json:"timedOut"`
+	Skipped    int            `json:"skipped"`
+	Sources    []SourceReport `json:"sources"`
+}
+
+// SourceReport describes how fetching a single source went
+type SourceReport struct {
+	Source     string      `json:"source"`
+	Status     FetchStatus `json:"status"`
+	DurationMS int64       `json:"durationMs"`
+	Items      int         `json:"items"`
+	Retries    int         `json:"retries"`
+
+	// ItemFailures counts individual items a source could not fetch while
+	// the source as a whole still succeeded
+	ItemFailures int `json:"itemFailures,omitempty"`
+
+	ErrorClass ErrorClass `json:"errorClass,omitempty"`
+	HTTPStatus int        `json:"httpStatus,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// HTTPStatusError reports an unexpected HTTP response status
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+
+	// RetryAfter is how long the response asked clients to wait, if it did
+	RetryAfter time.Duration
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d for %s", e.StatusCode, e.URL)
+}
+
+// ParseError reports a response body that could not be decoded
+type ParseError struct {
+	URL string
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse %s: %v", e.URL, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// SkippedError reports a source that chose not to fetch, such as a page
+// robots.txt disallows. It is reported as skipped rather than failed.
+type SkippedError struct {
+	URL    string
+	Reason string
+}
+
+func (e *SkippedError) Error() string {
+	return fmt.Sprintf("skipped %s: %s", e.URL, e.Reason)
+}
+
+// ClassifyError maps a fetch error to its class. For HTTP status errors
+// the status code is returned as well.
+func ClassifyError(err error) (ErrorClass, int) {
+	var statusErr *HTTPStatusError
+	var parseErr *ParseError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout, 0
+	case errors.As(err, &statusErr):
+		return ErrorClassHTTPStatus, statusErr.StatusCode
+	case errors.As(err, &parseErr), errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return ErrorClassParse, 0
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrorClassTimeout, 0
+		}
+		return ErrorClassNetwork, 0
+	default:
+		return ErrorClassOther, 0
+	}
+}
+
+// fetchStats collects counters a source reports while it is being fetched
+type fetchStats struct {
+	retries      int64
+	itemFailures int64
+}
+
+type fetchStatsKey struct{}
+
+// withFetchStats returns a context that sources can report statistics into
+func withFetchStats(ctx context.Context, stats *fetchStats) context.Context {
+	return context.WithValue(ctx, fetchStatsKey{}, stats)
+}
+
+// RecordRetry notes that a source retried a request. It is a no-op when
+// ctx does not come from FetchAll.
+func RecordRetry(ctx context.Context) {
+	if stats, ok := ctx.Value(fetchStatsKey{}).(*fetchStats); ok {
+		atomic.AddInt64(&stats.retries, 1)
+	}
+}
+
+// RecordItemFailure notes that a source failed to fetch one of several
+// items. It is a no-op when ctx does not come from FetchAll.
+func RecordItemFailure(ctx context.Context) {
+	if stats, ok := ctx.Value(fetchStatsKey{}).(*fetchStats); ok {
+		atomic.AddInt64(&stats.itemFailures, 1)
+	}
+}
+
+// newSourceReport builds the report entry for a finished fetch
+func newSourceReport(name string, items int, err error, elapsed time.Duration, stats *fetchStats) SourceReport {
+	report := SourceReport{
+		Source:     name,
+		Status:     StatusOK,
+		DurationMS: elapsed.Milliseconds(),
+		Items:      items,
+		Retries:    int(atomic.LoadInt64(&stats.retries)),
+
+		ItemFailures: int(atomic.LoadInt64(&stats.itemFailures)),
+	}
+
+	var skipped *SkippedError
+	if errors.As(err, &skipped) {
+		report.Items = 0
+		report.Error = err.Error()
+		report.Status = StatusSkipped
+	} else if err != nil {
+		report.Items = 0
+		report.Error = err.Error()
+		report.ErrorClass, report.HTTPStatus = ClassifyError(err)
+		report.Status = StatusError
+		if report.ErrorClass == ErrorClassTimeout {
+			report.Status = StatusTimeout
+		}
+	}
+
+	return report
+}
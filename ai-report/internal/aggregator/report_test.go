@@ -0,0 +1,106 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// stubSource is a source whose fetch is the given function
+type stubSource struct {
+	name  string
+	fetch func(ctx context.Context) ([]RawNewsItem, error)
+}
+
+func (s stubSource) FetchNews(ctx context.Context) ([]RawNewsItem, error) { return s.fetch(ctx) }
+func (s stubSource) GetName() string                                      { return s.name }
+
+func okSource(name string, n int) Source {
+	return stubSource{name, func(ctx context.Context) ([]RawNewsItem, error) {
+		items := make([]RawNewsItem, n)
+		for i := range items {
+			items[i] = RawNewsItem{Title: name, Source: name}
+		}
+		return items, nil
+	}}
+}
+
+func failingSource(name string, err error) Source {
+	return stubSource{name, func(ctx context.Context) ([]RawNewsItem, error) { return nil, err }}
+}
+
+// hangingSource blocks until its context ends
+func hangingSource(name string) Source {
+	return stubSource{name, func(ctx context.Context) ([]RawNewsItem, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+}
+
+func TestFetchReport(t *testing.T) {
+	a := New()
+	a.AddSource(stubSource{"Flaky", func(ctx context.Context) ([]RawNewsItem, error) {
+		RecordRetry(ctx)
+		RecordItemFailure(ctx)
+		return []RawNewsItem{{Title: "One"}, {Title: "Two"}}, nil
+	}})
+	a.AddSource(failingSource("Down", &HTTPStatusError{URL: "https://example.com/feed", StatusCode: 503}))
+	a.AddSourceWithTimeout(hangingSource("Slow"), 50*time.Millisecond)
+	a.AddSource(failingSource("Disallowed", &SkippedError{URL: "https://example.com/", Reason: "disallowed by robots.txt"}))
+
+	items, report, err := a.FetchAll(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAll: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("got %d items, want the 2 from the source that worked", len(items))
+	}
+	if report.OK != 1 || report.Failed != 1 || report.TimedOut != 1 || report.Skipped != 1 {
+		t.Errorf("report counts ok %d, failed %d, timed out %d, skipped %d, want one of each",
+			report.OK, report.Failed, report.TimedOut, report.Skipped)
+	}
+
+	want := []SourceReport{
+		{Source: "Flaky", Status: StatusOK, Items: 2, Retries: 1, ItemFailures: 1},
+		{Source: "Down", Status: StatusError, ErrorClass: ErrorClassHTTPStatus, HTTPStatus: 503, Error: "HTTP 503 for https://example.com/feed"},
+		{Source: "Slow", Status: StatusTimeout, ErrorClass: ErrorClassTimeout, Error: "context deadline exceeded"},
+		{Source: "Disallowed", Status: StatusSkipped, Error: "skipped https://example.com/: disallowed by robots.txt"},
+	}
+	for i, got := range report.Sources {
+		got.DurationMS = 0
+		if got != want[i] {
+			t.Errorf("source %d = %+v\nwant %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestFetchAllFailsOnlyWhenEverySourceFails(t *testing.T) {
+	down := &HTTPStatusError{URL: "https://example.com/feed", StatusCode: 500}
+	skipped := &SkippedError{URL: "https://example.com/", Reason: "disallowed by robots.txt"}
+
+	tests := []struct {
+		name    string
+		sources []Source
+		fail    bool
+	}{
+		{"no sources", nil, false},
+		{"every source skipped", []Source{failingSource("A", skipped), failingSource("B", skipped)}, false},
+		{"skipped and failed", []Source{failingSource("A", skipped), failingSource("B", down)}, false},
+		{"failed and timed out", []Source{failingSource("A", down), hangingSource("B")}, true},
+		{"one ok", []Source{okSource("A", 1), failingSource("B", down)}, false},
+	}
+
+	for _, tt := range tests {
+		a := New(WithSourceTimeout(50 * time.Millisecond))
+		for _, src := range tt.sources {
+			a.AddSource(src)
+		}
+		_, report, err := a.FetchAll(context.Background())
+		if (err != nil) != tt.fail {
+			t.Errorf("%s: FetchAll error = %v, want failure %v", tt.name, err, tt.fail)
+		}
+		if report == nil || len(report.Sources) != len(tt.sources) {
+			t.Errorf("%s: report = %+v, want one entry per source", tt.name, report)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package aggregator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Scorer rates one aspect of an item's relevance. The pipeline multiplies
+// each scorer's result by its weight and sums them into RawNewsItem.Score.
+type Scorer interface {
+	// Name identifies the scorer in configuration and score breakdowns
+	Name() string
+	Score(item *RawNewsItem, batch *Batch) float64
+}
+
+// Batch is the set of items being ranked together, for scorers that
+// compare items against each other
+type Batch struct {
+	Items []RawNewsItem
+	Now   time.Time
+}
+
+// NewBatch prepares items for scoring at the given time
+func NewBatch(items []RawNewsItem, now time.Time) *Batch {
+	return &Batch{Items: items, Now: now}
+}
+
+// ScoreBreakdown records the weighted contribution of each scorer to an
+// item's score, keyed by scorer name
+type ScoreBreakdown map[string]float64
+
+// String lists the contributions in a stable order, largest first
+func (b ScoreBreakdown) String() string {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if b[names[i]] != b[names[j]] {
+			return b[names[i]] > b[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%.2f", name, b[name])
+	}
+	return strings.Join(parts, " ")
+}
+
+// WeightedScorer is a pipeline stage
+type WeightedScorer struct {
+	Scorer Scorer
+	Weight float64
+}
+
+// Pipeline is an ordered list of weighted scorers
+type Pipeline []WeightedScorer
+
+// Score sets Score and ScoreBreakdown on every item in place
+func (p Pipeline) Score(items []RawNewsItem, now time.Time) {
+	batch := NewBatch(items, now)
+	for i := range items {
+		total := 0.0
+		breakdown := make(ScoreBreakdown, len(p))
+		for _, stage := range p {
+			if stage.Weight == 0 {
+				continue
+			}
+			contribution := stage.Weight * stage.Scorer.Score(&items[i], batch)
+			breakdown[stage.Scorer.Name()] = contribution
+			total += contribution
+		}
+		items[i].Score = total
+		items[i].ScoreBreakdown = breakdown
+	}
+}
+
+// WithWeights returns a copy of the pipeline with the named scorers
+// reweighted. A weight of 0 disables a scorer.
+func (p Pipeline) WithWeights(weights map[string]float64) (Pipeline, error) {
+	out := make(Pipeline, len(p))
+	copy(out, p)
+
+	for name, weight := range weights {
+		if weight < 0 {
+			return nil, fmt.Errorf("scorer %q has a negative weight", name)
+		}
+		found := false
+		for i := range out {
+			if out[i].Scorer.Name() == name {
+				out[i].Weight = weight
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown scorer %q (known scorers: %s)", name, strings.Join(p.names(), ", "))
+		}
+	}
+
+	return out, nil
+}
+
+// Replace returns a copy of the pipeline with the scorer of the same name
+// swapped for s, keeping its weight
+func (p Pipeline) Replace(s Scorer) Pipeline {
+	out := make(Pipeline, len(p))
+	copy(out, p)
+	for i := range out {
+		if out[i].Scorer.Name() == s.Name() {
+			out[i].Scorer = s
+		}
+	}
+	return out
+}
+
+func (p Pipeline) names() []string {
+	names := make([]string, len(p))
+	for i, stage := range p {
+		names[i] = stage.Scorer.Name()
+	}
+	return names
+}
+
+// DefaultKeywords are the terms the keyword scorer looks for
+var DefaultKeywords = []string{
+	"GPT", "ChatGPT", "Claude", "Gemini", "LLM", "AI", "artificial intelligence",
+	"machine learning", "deep learning", "neural network", "OpenAI", "Anthropic",
+	"Google AI", "DeepMind", "Microsoft AI", "Meta AI", "AGI", "AI safety",
+	"AI regulation", "AI ethics", "transformer", "diffusion model", "BREAKING",
+	"EXCLUSIVE", "URGENT", "breakthrough", "revolutionary", "unprecedented",
+}
+
+// DefaultTrustedSources are source name fragments the trust scorer boosts
+var DefaultTrustedSources = []string{"OpenAI", "Anthropic", "Google", "DeepMind", "MIT", "Stanford"}
+
+// DefaultPipeline scores capped keyword matches, a boost for trusted
+// sources, normalised engagement and the number of sources covering a
+// story. Age is handled by Decay after the pipeline runs, so the recency
+// buckets are available but off by default.
+func DefaultPipeline() Pipeline {
+	return Pipeline{
+		{Scorer: NewKeywordScorer(DefaultKeywords), Weight: 1},
+		{Scorer: RecencyScorer{}, Weight: 0},
+		{Scorer: NewTrustScorer(DefaultTrustedSources), Weight: 1},
+		{Scorer: EngagementScorer{}, Weight: 1},
+		{Scorer: CorroborationScorer{}, Weight: 1},
+	}
+}
+
+// KeywordScorer awards points per keyword found in the title and
+// description. The total is capped so stuffing a headline with keywords
+// cannot outweigh what readers actually engaged with.
+type KeywordScorer struct {
+	Keywords          []string
+	TitlePoints       float64
+	DescriptionPoints float64
+	Max               float64 // 0 means uncapped
+}
+
+// NewKeywordScorer scores 2 points per keyword in the title and 1 per
+// keyword in the description, up to 10
+func NewKeywordScorer(keywords []string) *KeywordScorer {
+	return &KeywordScorer{Keywords: keywords, TitlePoints: 2, DescriptionPoints: 1, Max: 10}
+}
+
+func (KeywordScorer) Name() string { return "keyword" }
+
+func (k *KeywordScorer) Score(item *RawNewsItem, _ *Batch) float64 {
+	titleLower := strings.ToLower(item.Title)
+	descLower := strings.ToLower(item.Description)
+
+	score := 0.0
+	for _, keyword := range k.Keywords {
+		keywordLower := strings.ToLower(keyword)
+		if strings.Contains(titleLower, keywordLower) {
+			score += k.TitlePoints
+		}
+		if strings.Contains(descLower, keywordLower) {
+			score += k.DescriptionPoints
+		}
+	}
+
+	if k.Max > 0 {
+		score = math.Min(score, k.Max)
+	}
+	return score
+}
+
+// RecencyScorer favours fresh items: 5 points in the first hour, 3 up to
+// six hours and 1 up to a day. Undated items get nothing. Superseded by
+// Decay, it is kept for configurations that want the step function.
+type RecencyScorer struct{}
+
+func (RecencyScorer) Name() string { return "recency" }
+
+func (RecencyScorer) Score(item *RawNewsItem, batch *Batch) float64 {
+	if item.PublishedAt.IsZero() {
+		return 0
+	}
+	hoursSince := batch.Now.Sub(item.PublishedAt).Hours()
+	switch {
+	case hoursSince < 1:
+		return 5
+	case hoursSince < 6:
+		return 3
+	case hoursSince < 24:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// TrustScorer boosts items from sources whose name contains one of Sources
+type TrustScorer struct {
+	Sources []string
+	Points  float64
+}
+
+// NewTrustScorer awards 2 points to items from the given sources
+func NewTrustScorer(sources []string) *TrustScorer {
+	return &TrustScorer{Sources: sources, Points: 2}
+}
+
+func (TrustScorer) Name() string { return "trust" }
+
+func (t *TrustScorer) Score(item *RawNewsItem, _ *Batch) float64 {
+	for _, trusted := range t.Sources {
+		if strings.Contains(item.Source, trusted) {
+			return t.Points
+		}
+	}
+	return 0
+}
+
+// EngagementScorer rewards audience engagement, normalised per platform
+type EngagementScorer struct{}
+
+func (EngagementScorer) Name() string { return "engagement" }
+
+func (EngagementScorer) Score(item *RawNewsItem, _ *Batch) float64 {
+	return engagementWeight * normalizedEngagement(item.Engagement)
+}
+
+// CorroborationScorer awards 2 points for every other source carrying the
+// same story, up to 6. It reads the coverage clustering records on items.
+type CorroborationScorer struct{}
+
+func (CorroborationScorer) Name() string { return "corroboration" }
+
+func (CorroborationScorer) Score(item *RawNewsItem, _ *Batch) float64 {
+	others := item.Coverage - 1
+	if others <= 0 {
+		return 0
+	}
+	return math.Min(2*float64(others), 6)
+}
@@ -0,0 +1,26 @@
+// Package clock abstracts the current time so runs can be reproduced
+package clock
+
+import "time"
+
+// Clock tells the time. Code that ranks, filters or stamps items by age
+// reads it instead of calling time.Now directly.
+type Clock interface {
+	Now() time.Time
+}
+
+// Func adapts a function to the Clock interface
+type Func func() time.Time
+
+// Now calls f
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// Real is the system clock
+var Real Clock = Func(time.Now)
+
+// Fixed returns a clock stopped at t
+func Fixed(t time.Time) Clock {
+	return Func(func() time.Time { return t })
+}
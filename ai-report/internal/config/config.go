@@ -0,0 +1,374 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config represents the aggregator configuration file
+type Config struct {
+	Path string
+
+	// Timeout caps the whole fetch phase; SourceTimeout is the default
+	// deadline for a single source. Zero disables the respective limit.
+	Timeout       time.Duration
+	SourceTimeout time.Duration
+
+	Scoring    Scoring
+	Clustering Clustering
+	Categories Categories
+	Diversity  Diversity
+	Headlines  Headlines
+	Store      Store
+	Fetch      Fetch
+	Scraper    Scraper
+	Retry      map[string]Retry
+
+	Sources []SourceSpec
+}
+
+// Clustering tunes how items covering the same story are grouped.
+// Threshold is the title and description similarity, from 0 to 1, at which
+// items are merged; 0 merges only identical URLs and titles. RelatedLinks
+// caps the other sources' links listed under a headline. Unset fields keep
+// their defaults.
+type Clustering struct {
+	Threshold    *float64 `yaml:"threshold"`
+	RelatedLinks *int     `yaml:"relatedLinks"`
+}
+
+// Categories tunes how items are filed under topics and which column shows
+// each topic. Rules are keyed by category; a rule's keywords or domains
+// replace that category's default list when set. Default names the
+// category for items no rule matches. Columns left unset keep their
+// default categories.
+type Categories struct {
+	Default string                  `yaml:"default"`
+	Rules   map[string]CategoryRule `yaml:"rules"`
+	Columns Columns                 `yaml:"columns"`
+}
+
+// CategoryRule lists the keywords and URL domains that file an item under
+// a category
+type CategoryRule struct {
+	Keywords []string `yaml:"keywords"`
+	Domains  []string `yaml:"domains"`
+}
+
+// Columns lists the categories shown in each output column
+type Columns struct {
+	Left   []string `yaml:"left"`
+	Center []string `yaml:"center"`
+	Right  []string `yaml:"right"`
+}
+
+// Diversity caps the items one source or site may place in a section of
+// the page: the headline and top stories together, with the top limits,
+// or a single column. 0 means no limit; unset fields keep their defaults.
+type Diversity struct {
+	TopPerSource *int `yaml:"topPerSource"`
+	TopPerDomain *int `yaml:"topPerDomain"`
+	PerSource    *int `yaml:"perSource"`
+	PerDomain    *int `yaml:"perDomain"`
+}
+
+// Headlines tunes how titles are tidied for display. SiteNames are trimmed
+// from the end of titles in addition to the built-in list and the
+// sources' own names. Rewrites run in order after cleaning. The emphasis
+// lists replace the defaults when set.
+type Headlines struct {
+	SiteNames []string  `yaml:"siteNames"`
+	Rewrites  []Rewrite `yaml:"rewrites"`
+	Emphasis  Emphasis  `yaml:"emphasis"`
+}
+
+// Rewrite replaces matches of the regular expression Pattern with Replace
+type Rewrite struct {
+	Pattern string `yaml:"pattern"`
+	Replace string `yaml:"replace"`
+}
+
+// Emphasis sets headlines in capitals: those containing a word from Always,
+// and those shorter than MaxLength mentioning one of Topics
+type Emphasis struct {
+	Always    []string `yaml:"always"`
+	Topics    []string `yaml:"topics"`
+	MaxLength *int     `yaml:"maxLength"`
+}
+
+// Store tunes the item store kept in the cache directory. Retention is how
+// long an item is remembered after it was last seen; unset keeps the
+// default.
+type Store struct {
+	Retention time.Duration `yaml:"retention"`
+}
+
+// Fetch limits the load put on each host. Concurrency caps the requests
+// in flight to a host and RequestsPerSecond how many start each second;
+// Hosts sets other limits for the hosts it names. MaxRetryAfter is the
+// longest Retry-After that is waited out and retried. Unset fields keep
+// their defaults and 0 means no limit.
+type Fetch struct {
+	Concurrency       *int                  `yaml:"concurrency"`
+	RequestsPerSecond *float64              `yaml:"requestsPerSecond"`
+	MaxRetryAfter     *time.Duration        `yaml:"maxRetryAfter"`
+	Hosts             map[string]HostLimits `yaml:"hosts"`
+}
+
+// HostLimits are the fetch limits of one host. Unset fields keep the
+// limits that apply to every host.
+type HostLimits struct {
+	Concurrency       *int     `yaml:"concurrency"`
+	RequestsPerSecond *float64 `yaml:"requestsPerSecond"`
+}
+
+// Scraper sets the identity scraped sites see and match their robots.txt
+// against: UserAgent's product token, the part before "/", names the bot
+// and Contact is a URL where site owners can learn about it. Unset fields
+// keep their defaults.
+type Scraper struct {
+	UserAgent string `yaml:"userAgent"`
+	Contact   string `yaml:"contact"`
+}
+
+// Retry tunes how the sources of one type retry requests that failed for
+// a reason likely to pass. Attempts counts the first try; BaseDelay is the
+// wait before the first retry, doubled for each further one up to
+// MaxDelay. Unset fields keep the type's defaults.
+type Retry struct {
+	Attempts  *int          `yaml:"attempts"`
+	BaseDelay time.Duration `yaml:"baseDelay"`
+	MaxDelay  time.Duration `yaml:"maxDelay"`
+}
+
+// Scoring tunes how items are ranked. Weights are keyed by scorer name;
+// scorers left out keep their default weight. Keywords and TrustedSources
+// replace the default lists when set.
+type Scoring struct {
+	Weights        map[string]float64 `yaml:"weights"`
+	Keywords       []string           `yaml:"keywords"`
+	TrustedSources []string           `yaml:"trustedSources"`
+	Decay          Decay              `yaml:"decay"`
+}
+
+// Decay tunes how scores fall off with age. Unset fields keep their
+// defaults.
+type Decay struct {
+	Mode       string        `yaml:"mode"` // none, gravity or halflife
+	Gravity    float64       `yaml:"gravity"`
+	HalfLife   time.Duration `yaml:"halfLife"`
+	MissingAge time.Duration `yaml:"missingAge"`
+}
+
+// SourceSpec describes a single source entry in the configuration file.
+// Type selects the source implementation; the remaining keys of the entry
+// are the type-specific options and are decoded with Decode.
+type SourceSpec struct {
+	Type    string        `yaml:"type"`
+	Timeout time.Duration `yaml:"timeout"`
+	Line    int           `yaml:"-"`
+
+	// Category, when set, is the topic the source's items usually belong
+	// to, a hint for the categoriser
+	Category string `yaml:"category"`
+
+	path string
+	node *yaml.Node
+}
+
+// commonKeys are the keys every source entry may carry regardless of type
+var commonKeys = map[string]bool{
+	"type":     true,
+	"timeout":  true,
+	"category": true,
+}
+
+// rawConfig mirrors the top level of the configuration file
+type rawConfig struct {
+	Timeout       time.Duration    `yaml:"timeout"`
+	SourceTimeout time.Duration    `yaml:"sourceTimeout"`
+	Scoring       Scoring          `yaml:"scoring"`
+	Clustering    Clustering       `yaml:"clustering"`
+	Categories    Categories       `yaml:"categories"`
+	Diversity     Diversity        `yaml:"diversity"`
+	Headlines     Headlines        `yaml:"headlines"`
+	Store         Store            `yaml:"store"`
+	Fetch         Fetch            `yaml:"fetch"`
+	Scraper       Scraper          `yaml:"scraper"`
+	Retry         map[string]Retry `yaml:"retry"`
+	Sources       []yaml.Node      `yaml:"sources"`
+}
+
+// Load reads and validates the configuration file at path
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	return Parse(path, data)
+}
+
+// Parse validates configuration data. Every invalid source entry is
+// reported, not just the first. The path is only used in error messages.
+func Parse(path string, data []byte) (*Config, error) {
+	var raw rawConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&raw); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if raw.Store.Retention < 0 {
+		return nil, fmt.Errorf("%s: store retention must not be negative", path)
+	}
+	if raw.Timeout < 0 || raw.SourceTimeout < 0 {
+		return nil, fmt.Errorf("%s: timeouts must not be negative", path)
+	}
+	if t := raw.Clustering.Threshold; t != nil && (*t < 0 || *t > 1) {
+		return nil, fmt.Errorf("%s: clustering threshold must be between 0 and 1", path)
+	}
+	if n := raw.Clustering.RelatedLinks; n != nil && *n < 0 {
+		return nil, fmt.Errorf("%s: clustering relatedLinks must not be negative", path)
+	}
+
+	cfg := &Config{
+		Path:          path,
+		Timeout:       raw.Timeout,
+		SourceTimeout: raw.SourceTimeout,
+		Scoring:       raw.Scoring,
+		Clustering:    raw.Clustering,
+		Categories:    raw.Categories,
+		Diversity:     raw.Diversity,
+		Headlines:     raw.Headlines,
+		Store:         raw.Store,
+		Fetch:         raw.Fetch,
+		Scraper:       raw.Scraper,
+		Retry:         raw.Retry,
+	}
+	// Check every source entry, so one run reports all the bad ones
+	var errs []error
+	for i := range raw.Sources {
+		node := &raw.Sources[i]
+		spec := SourceSpec{Line: node.Line, path: path, node: node}
+
+		if node.Kind != yaml.MappingNode {
+			errs = append(errs, spec.Errorf("source %d must be a mapping", i+1))
+			continue
+		}
+		if err := node.Decode(&spec); err != nil {
+			errs = append(errs, spec.Errorf("source %d: %v", i+1, err))
+			continue
+		}
+		if spec.Type == "" {
+			errs = append(errs, spec.Errorf("source %d is missing a type", i+1))
+			continue
+		}
+		if spec.Timeout < 0 {
+			errs = append(errs, spec.Errorf("source %d has a negative timeout", i+1))
+			continue
+		}
+
+		cfg.Sources = append(cfg.Sources, spec)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if len(cfg.Sources) == 0 {
+		return nil, fmt.Errorf("%s: no sources configured", path)
+	}
+
+	return cfg, nil
+}
+
+// Decode decodes the type-specific options of the source into v, which
+// must be a pointer to a struct. Keys that are neither common keys nor
+// fields of v, at any nesting level, are reported with their line number.
+func (s *SourceSpec) Decode(v interface{}) error {
+	if err := s.checkKeys(s.node, reflect.TypeOf(v), commonKeys); err != nil {
+		return err
+	}
+
+	if err := s.node.Decode(v); err != nil {
+		return s.Errorf("%v", err)
+	}
+	return nil
+}
+
+// checkKeys reports the first mapping key in node that t has no field for
+func (s *SourceSpec) checkKeys(node *yaml.Node, t reflect.Type, allowed map[string]bool) error {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		if t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode {
+			for _, elem := range node.Content {
+				if err := s.checkKeys(elem, t.Elem(), nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	fields := yamlFields(t)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if allowed[key.Value] {
+			continue
+		}
+		fieldType, ok := fields[key.Value]
+		if !ok {
+			return fmt.Errorf("%s:%d: unknown option %q for %s source", s.path, key.Line, key.Value, s.Type)
+		}
+		if err := s.checkKeys(value, fieldType, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Errorf returns an error prefixed with the file and line of the source entry
+func (s *SourceSpec) Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d: %s", s.path, s.Line, fmt.Sprintf(format, args...))
+}
+
+// yamlFields maps the YAML keys a struct type accepts to their field types
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+
+		name := strings.ToLower(field.Name)
+		if tag, ok := field.Tag.Lookup("yaml"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+		}
+		fields[name] = field.Type
+	}
+
+	return fields
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReportsEveryBadSource(t *testing.T) {
+	_, err := Parse("config.yaml", []byte(`timeout: 30s
+sources:
+  - just a string
+  - name: No type
+    url: https://example.com/feed
+  - type: rss
+    timeout: -5s
+  - type: rss
+    name: Fine
+    url: https://example.com/feed
+  - type: [rss]
+`))
+	if err == nil {
+		t.Fatal("Parse accepted bad sources")
+	}
+
+	for _, want := range []string{
+		"config.yaml:3: source 1 must be a mapping",
+		"config.yaml:4: source 2 is missing a type",
+		"config.yaml:6: source 3 has a negative timeout",
+		"config.yaml:11: source 5:",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not report %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "source 4") {
+		t.Errorf("error %q reports the valid source", err)
+	}
+}
+
+func TestParseUnknownTopLevelKey(t *testing.T) {
+	_, err := Parse("config.yaml", []byte("timeout: 30s\nsoruces: []\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") || !strings.Contains(err.Error(), "soruces") {
+		t.Errorf("Parse error = %v, want the unknown key and its line", err)
+	}
+}
+
+func TestParseNoSources(t *testing.T) {
+	if _, err := Parse("config.yaml", []byte("timeout: 30s\n")); err == nil || !strings.Contains(err.Error(), "no sources configured") {
+		t.Errorf("Parse error = %v, want no sources configured", err)
+	}
+}
+
+// testFeed stands in for a source type's options
+type testFeed struct {
+	Name    string `yaml:"name"`
+	URL     string `yaml:"url"`
+	Mirrors []struct {
+		URL string `yaml:"url"`
+	} `yaml:"mirrors"`
+	Extra *struct {
+		Depth int `yaml:"depth"`
+	} `yaml:"extra"`
+	Internal string `yaml:"-"`
+}
+
+func TestSourceSpecDecodeUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"top level", "  - type: rss\n    name: Feed\n    nmae: Typo\n", `config.yaml:5: unknown option "nmae" for rss source`},
+		{"in a list", "  - type: rss\n    mirrors:\n      - url: https://a.example.com\n      - uri: https://b.example.com\n", `config.yaml:6: unknown option "uri" for rss source`},
+		{"in a nested struct", "  - type: rss\n    extra:\n      depth: 3\n      width: 4\n", `config.yaml:6: unknown option "width" for rss source`},
+		{"ignored field", "  - type: rss\n    internal: x\n", `config.yaml:4: unknown option "internal" for rss source`},
+		{"common keys", "  - type: rss\n    timeout: 5s\n    category: research\n    name: Feed\n", ""},
+	}
+
+	for _, tt := range tests {
+		cfg, err := Parse("config.yaml", []byte("timeout: 30s\nsources:\n"+tt.yaml))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var feed testFeed
+		err = cfg.Sources[0].Decode(&feed)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%s: Decode = %v, want no error", tt.name, err)
+		case tt.want != "" && (err == nil || err.Error() != tt.want):
+			t.Errorf("%s: Decode = %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
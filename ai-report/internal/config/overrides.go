@@ -0,0 +1,107 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Overrides is the editorial overrides file: stories pinned to a slot,
+// score boosts and bans. Every entry may carry an expiry and a note that is
+// logged when it is applied.
+type Overrides struct {
+	Path string `yaml:"-"`
+
+	Pins   []Pin   `yaml:"pins"`
+	Boosts []Boost `yaml:"boosts"`
+	Bans   []Ban   `yaml:"bans"`
+}
+
+// Pin holds the story at URL in Slot (headline, top, left, center or
+// right). Title is shown if the story is not among the run's items.
+type Pin struct {
+	URL     string    `yaml:"url"`
+	Slot    string    `yaml:"slot"`
+	Title   string    `yaml:"title"`
+	Expires time.Time `yaml:"expires"`
+	Note    string    `yaml:"note"`
+}
+
+// Boost adds Delta to the score of the item at URL or of items with
+// Keyword in their title
+type Boost struct {
+	URL     string    `yaml:"url"`
+	Keyword string    `yaml:"keyword"`
+	Delta   float64   `yaml:"delta"`
+	Expires time.Time `yaml:"expires"`
+	Note    string    `yaml:"note"`
+}
+
+// Ban drops the item at URL, items from Domain, or items whose title
+// matches the regular expression Title
+type Ban struct {
+	URL     string    `yaml:"url"`
+	Domain  string    `yaml:"domain"`
+	Title   string    `yaml:"title"`
+	Expires time.Time `yaml:"expires"`
+	Note    string    `yaml:"note"`
+}
+
+// LoadOverrides reads and checks the overrides file at path. A missing
+// file means there are no overrides.
+func LoadOverrides(path string) (*Overrides, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Overrides{Path: path}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides %s: %w", path, err)
+	}
+
+	return ParseOverrides(path, data)
+}
+
+// ParseOverrides checks overrides data. The path is only used in error
+// messages.
+func ParseOverrides(path string, data []byte) (*Overrides, error) {
+	o := &Overrides{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(o); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	o.Path = path
+
+	for i, p := range o.Pins {
+		if p.URL == "" || p.Slot == "" {
+			return nil, fmt.Errorf("%s: pin %d needs a url and a slot", path, i+1)
+		}
+	}
+	for i, b := range o.Boosts {
+		if (b.URL == "") == (b.Keyword == "") {
+			return nil, fmt.Errorf("%s: boost %d needs either a url or a keyword", path, i+1)
+		}
+		if b.Delta == 0 {
+			return nil, fmt.Errorf("%s: boost %d needs a non-zero delta", path, i+1)
+		}
+	}
+	for i, b := range o.Bans {
+		set := 0
+		for _, field := range []string{b.URL, b.Domain, b.Title} {
+			if field != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, fmt.Errorf("%s: ban %d needs exactly one of url, domain or title", path, i+1)
+		}
+	}
+
+	return o, nil
+}
@@ -0,0 +1,187 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ai-report/aggregator/internal/aggregator"
+)
+
+// rediscoverAfter is how long a site known to have no feed is scraped
+// before discovery is attempted again
+const rediscoverAfter = 7 * 24 * time.Hour
+
+// feedLinkTypes are the <link rel="alternate"> types treated as feeds
+var feedLinkTypes = []string{
+	"application/rss+xml",
+	"application/atom+xml",
+	"application/feed+json",
+}
+
+// commonFeedPaths are probed, relative to the page and to the site root,
+// when a page does not advertise a feed
+var commonFeedPaths = []string{"feed", "index.xml", "atom.xml", "rss.xml", "feed.xml"}
+
+// FeedCache remembers the feed discovered for each scraped site, or that a
+// site has none, so discovery runs once rather than on every fetch
+type FeedCache struct {
+	path  string
+	mu    sync.Mutex
+	Sites map[string]DiscoveredFeed `json:"sites"`
+}
+
+// DiscoveredFeed is the discovery result for a site. An empty FeedURL
+// records that no feed was found.
+type DiscoveredFeed struct {
+	FeedURL   string    `json:"feedUrl,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// NewFeedCache creates an in-memory feed cache
+func NewFeedCache() *FeedCache {
+	return &FeedCache{Sites: make(map[string]DiscoveredFeed)}
+}
+
+// LoadFeedCache reads the feed cache at path. A missing file yields an
+// empty cache that Save will create. An unreadable file is reported, but
+// an empty cache bound to path is still returned so Save replaces it.
+func LoadFeedCache(path string) (*FeedCache, error) {
+	cache := NewFeedCache()
+	cache.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return cache, fmt.Errorf("failed to read feed cache: %w", err)
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Sites == nil {
+		cache.Sites = make(map[string]DiscoveredFeed)
+		if err != nil {
+			return cache, fmt.Errorf("failed to parse feed cache %s: %w", path, err)
+		}
+	}
+
+	return cache, nil
+}
+
+// Save writes the cache back to the file it was loaded from
+func (c *FeedCache) Save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal feed cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// lookup returns the discovery result for a site
+func (c *FeedCache) lookup(site string) (DiscoveredFeed, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.Sites[site]
+	return entry, ok
+}
+
+// store records the discovery result for a site, checked at now
+func (c *FeedCache) store(site, feedURL string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Sites[site] = DiscoveredFeed{FeedURL: feedURL, CheckedAt: now.UTC()}
+}
+
+// forget drops a site so discovery runs again on the next fetch
+func (c *FeedCache) forget(site string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.Sites, site)
+}
+
+// shouldDiscover reports whether discovery is due at now for a site
+// without a known feed
+func (c *FeedCache) shouldDiscover(site string, now time.Time) bool {
+	entry, ok := c.lookup(site)
+	return !ok || now.Sub(entry.CheckedAt) > rediscoverAfter
+}
+
+// feedCandidates lists the feed URLs to try for a page: advertised
+// alternate links first, then the common paths
+func feedCandidates(doc *goquery.Document, base *url.URL) []string {
+	seen := make(map[string]bool)
+	var candidates []string
+	add := func(href string) {
+		if u := resolveURL(base, href); u != "" && !seen[u] {
+			seen[u] = true
+			candidates = append(candidates, u)
+		}
+	}
+
+	doc.Find(`link[rel~="alternate"][href]`).Each(func(_ int, link *goquery.Selection) {
+		linkType, _ := link.Attr("type")
+		linkType = strings.ToLower(strings.TrimSpace(linkType))
+		for _, t := range feedLinkTypes {
+			if linkType == t {
+				href, _ := link.Attr("href")
+				add(href)
+				return
+			}
+		}
+	})
+
+	for _, path := range commonFeedPaths {
+		add(path)
+	}
+	for _, path := range commonFeedPaths {
+		add("/" + path)
+	}
+
+	return candidates
+}
+
+// discoverFeed tries each candidate feed for the page and returns the first
+// that parses, along with its items
+func (w *WebScraperSource) discoverFeed(ctx context.Context, doc *goquery.Document, base *url.URL) (string, []aggregator.RawNewsItem, bool) {
+	for _, candidate := range feedCandidates(doc, base) {
+		if ctx.Err() != nil {
+			return "", nil, false
+		}
+
+		if err := w.robots.Check(ctx, candidate); err != nil {
+			continue
+		}
+		items, err := w.feedSource(candidate).fetchOnce(ctx)
+		if err == nil {
+			return candidate, items, true
+		}
+	}
+	return "", nil, false
+}
+
+// feedSource returns an RSS source that reads feedURL under the site's name
+// and the bot's user agent
+func (w *WebScraperSource) feedSource(feedURL string) *RSSSource {
+	source := NewRSSSource(RSSFeed{Name: w.scraper.Name, URL: feedURL})
+	source.clock = w.clock
+	source.fetcher = w.fetcher
+	source.header = w.robots.bot.header()
+	source.retry = w.retry
+	return source
+}
@@ -0,0 +1,263 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/clock"
+)
+
+var discoveryNow = time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+// blogSite is a stand-in blog serving pages by path and answering 404 for
+// everything else, robots.txt included. It records the paths requested.
+type blogSite struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	pages     map[string]string
+	requested []string
+}
+
+func newBlogSite(t *testing.T, pages map[string]string) *blogSite {
+	t.Helper()
+
+	site := &blogSite{pages: pages}
+	site.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site.mu.Lock()
+		site.requested = append(site.requested, r.URL.Path)
+		page, ok := site.pages[r.URL.Path]
+		site.mu.Unlock()
+
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, page)
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+// requestedPath reports whether path was requested since the last reset
+func (s *blogSite) requestedPath(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.requested {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *blogSite) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requested = nil
+}
+
+// testFeed is an RSS feed with one post from an hour before discoveryNow
+func testFeed(title string) string {
+	return `<?xml version="1.0"?><rss version="2.0"><channel><title>Blog</title>
+		<item><title>` + title + `</title><link>https://blog.example.com/feed-post</link>
+		<pubDate>Mon, 10 Jun 2024 11:00:00 GMT</pubDate></item>
+	</channel></rss>`
+}
+
+// scrapedPage is a blog index with one post from the day, and head in
+// its <head>
+func scrapedPage(head string) string {
+	return `<html><head>` + head + `</head><body>
+		<article><h2><a href="/scraped-post/">Scraped post</a></h2><time datetime="2024-06-10T09:00:00Z"></time></article>
+	</body></html>`
+}
+
+func newDiscoveryScraper(site *blogSite, feeds *FeedCache) *WebScraperSource {
+	fetcher := NewFetcher(FetchLimits{}, nil, false)
+	source := NewWebScraperSource(WebScraper{Name: "Blog", URL: site.URL + "/blog/"})
+	source.feeds = feeds
+	source.fetcher = fetcher
+	source.robots = NewRobots(DefaultBot(), fetcher)
+	source.retry = RetryPolicy{Attempts: 1}
+	source.clock = clock.Fixed(discoveryNow)
+	return source
+}
+
+func fetchTitles(t *testing.T, source *WebScraperSource) []string {
+	t.Helper()
+	items, err := source.FetchNews(context.Background())
+	if err != nil {
+		t.Fatalf("FetchNews: %v", err)
+	}
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	return titles
+}
+
+func TestFeedCandidates(t *testing.T) {
+	doc, base := parsePage(t, blogURL, `<html><head>
+		<link rel="alternate" type="text/html" href="/fr/">
+		<link rel="alternate" type="application/atom+xml" href="atom/">
+		<link rel="alternate nofollow" type=" Application/RSS+XML " href="https://feeds.example.com/blog.rss">
+		<link rel="alternate" type="application/feed+json" href="/feed.json">
+		<link rel="alternate" type="application/rss+xml" href="https://feeds.example.com/blog.rss">
+		<link rel="stylesheet" type="application/rss+xml" href="/not-a-feed.xml">
+	</head></html>`)
+
+	want := []string{
+		"https://blog.example.com/posts/atom/",
+		"https://feeds.example.com/blog.rss",
+		"https://blog.example.com/feed.json",
+		"https://blog.example.com/posts/feed",
+		"https://blog.example.com/posts/index.xml",
+		"https://blog.example.com/posts/atom.xml",
+		"https://blog.example.com/posts/rss.xml",
+		"https://blog.example.com/posts/feed.xml",
+		"https://blog.example.com/feed",
+		"https://blog.example.com/index.xml",
+		"https://blog.example.com/atom.xml",
+		"https://blog.example.com/rss.xml",
+		"https://blog.example.com/feed.xml",
+	}
+	if got := feedCandidates(doc, base); !reflect.DeepEqual(got, want) {
+		t.Errorf("feedCandidates =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestScraperDiscoversAdvertisedFeed(t *testing.T) {
+	site := newBlogSite(t, map[string]string{
+		"/blog/":          scrapedPage(`<link rel="alternate" type="application/rss+xml" href="/feeds/main.xml">`),
+		"/feeds/main.xml": testFeed("From the feed"),
+	})
+	feeds := NewFeedCache()
+	source := newDiscoveryScraper(site, feeds)
+
+	if got := fetchTitles(t, source); !reflect.DeepEqual(got, []string{"From the feed"}) {
+		t.Errorf("first fetch = %v, want the advertised feed's post", got)
+	}
+	want := DiscoveredFeed{FeedURL: site.URL + "/feeds/main.xml", CheckedAt: discoveryNow}
+	if got, _ := feeds.lookup(site.URL + "/blog/"); got != want {
+		t.Errorf("cached %+v, want %+v", got, want)
+	}
+
+	// Later runs read the feed without fetching the page
+	site.reset()
+	if got := fetchTitles(t, source); !reflect.DeepEqual(got, []string{"From the feed"}) {
+		t.Errorf("second fetch = %v, want the cached feed's post", got)
+	}
+	if site.requestedPath("/blog/") {
+		t.Error("page fetched again although its feed is cached")
+	}
+}
+
+func TestScraperDiscoversFeedAtCommonPath(t *testing.T) {
+	site := newBlogSite(t, map[string]string{
+		"/blog/":     scrapedPage(""),
+		"/index.xml": testFeed("From the root feed"),
+	})
+	feeds := NewFeedCache()
+
+	if got := fetchTitles(t, newDiscoveryScraper(site, feeds)); !reflect.DeepEqual(got, []string{"From the root feed"}) {
+		t.Errorf("fetch = %v, want the post from the feed at a common path", got)
+	}
+	if !site.requestedPath("/blog/feed") || !site.requestedPath("/feed") {
+		t.Error("common paths under the page were not tried before the site root")
+	}
+	if got, _ := feeds.lookup(site.URL + "/blog/"); got.FeedURL != site.URL+"/index.xml" {
+		t.Errorf("cached feed %q, want %q", got.FeedURL, site.URL+"/index.xml")
+	}
+}
+
+func TestScraperForgetsVanishedFeed(t *testing.T) {
+	site := newBlogSite(t, map[string]string{"/blog/": scrapedPage("")})
+	feeds := NewFeedCache()
+	feeds.store(site.URL+"/blog/", site.URL+"/gone.xml", discoveryNow.Add(-24*time.Hour))
+
+	if got := fetchTitles(t, newDiscoveryScraper(site, feeds)); !reflect.DeepEqual(got, []string{"Scraped post"}) {
+		t.Errorf("fetch = %v, want the page scraped", got)
+	}
+
+	// Discovery ran again, found nothing and recorded that
+	want := DiscoveredFeed{CheckedAt: discoveryNow}
+	if got, ok := feeds.lookup(site.URL + "/blog/"); !ok || got != want {
+		t.Errorf("cached %+v, %v, want %+v", got, ok, want)
+	}
+	if !site.requestedPath("/feed.xml") {
+		t.Error("discovery did not run again after the cached feed went away")
+	}
+}
+
+func TestScraperRediscoversAfterAWeek(t *testing.T) {
+	site := newBlogSite(t, map[string]string{
+		"/blog/": scrapedPage(""),
+		"/feed":  testFeed("New feed"),
+	})
+
+	tests := []struct {
+		checked time.Duration
+		want    string
+	}{
+		{6 * 24 * time.Hour, "Scraped post"},
+		{8 * 24 * time.Hour, "New feed"},
+	}
+	for _, tt := range tests {
+		feeds := NewFeedCache()
+		feeds.store(site.URL+"/blog/", "", discoveryNow.Add(-tt.checked))
+
+		if got := fetchTitles(t, newDiscoveryScraper(site, feeds)); !reflect.DeepEqual(got, []string{tt.want}) {
+			t.Errorf("no feed found %v ago: fetch = %v, want %q", tt.checked, got, tt.want)
+		}
+	}
+}
+
+func TestFeedCacheLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "feeds.json")
+
+	cache, err := LoadFeedCache(path)
+	if err != nil || len(cache.Sites) != 0 {
+		t.Fatalf("LoadFeedCache of a missing file = %+v, %v, want an empty cache", cache.Sites, err)
+	}
+	cache.store("https://a.example.com/", "https://a.example.com/feed", discoveryNow)
+	cache.store("https://b.example.com/", "", discoveryNow)
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadFeedCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Sites, cache.Sites) {
+		t.Errorf("loaded %+v, want %+v", loaded.Sites, cache.Sites)
+	}
+
+	// A damaged file is reported, and replaced by the next save
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	damaged, err := LoadFeedCache(path)
+	if err == nil || damaged.Sites == nil || len(damaged.Sites) != 0 {
+		t.Fatalf("LoadFeedCache of a damaged file = %+v, %v, want an error and an empty cache", damaged.Sites, err)
+	}
+	if err := damaged.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFeedCache(path); err != nil {
+		t.Errorf("cache saved over a damaged file does not load: %v", err)
+	}
+
+	if err := NewFeedCache().Save(); err != nil {
+		t.Errorf("Save of an in-memory cache = %v, want nothing written", err)
+	}
+}
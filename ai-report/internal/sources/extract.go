@@ -0,0 +1,324 @@
+package sources
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// dateLayouts are the date formats tried, in order, by parseDate
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"January 2006",
+	"2006/01/02",
+}
+
+// parseDate parses the date formats commonly found in blog markup
+func parseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// resolveURL makes href absolute relative to base
+func resolveURL(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if base == nil {
+		return ref.String()
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// datedPosts drops posts without a title, URL or publish date and removes
+// repeated URLs, keeping the first occurrence
+func datedPosts(posts []BlogPost) []BlogPost {
+	seen := make(map[string]bool)
+	kept := make([]BlogPost, 0, len(posts))
+
+	for _, post := range posts {
+		if post.Title == "" || post.URL == "" || post.PublishedAt.IsZero() || seen[post.URL] {
+			continue
+		}
+		seen[post.URL] = true
+		kept = append(kept, post)
+	}
+
+	return kept
+}
+
+// cleanText collapses whitespace in s
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// selectionDate returns the first parseable date in sel from <time> elements
+// or schema.org datePublished markup
+func selectionDate(sel *goquery.Selection) time.Time {
+	var found time.Time
+	sel.Find(`time, [itemprop="datePublished"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		for _, attr := range []string{"datetime", "content"} {
+			if v, ok := s.Attr(attr); ok {
+				if t, ok := parseDate(v); ok {
+					found = t
+					return false
+				}
+			}
+		}
+		if t, ok := parseDate(cleanText(s.Text())); ok {
+			found = t
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// jsonLD is the subset of schema.org fields read from JSON-LD blocks
+type jsonLD struct {
+	Type          interface{}       `json:"@type"`
+	Graph         []json.RawMessage `json:"@graph"`
+	Headline      string            `json:"headline"`
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	DatePublished string            `json:"datePublished"`
+	Description   string            `json:"description"`
+	Image         interface{}       `json:"image"`
+	BlogPost      []json.RawMessage `json:"blogPost"`
+	ItemListElem  []json.RawMessage `json:"itemListElement"`
+	Item          json.RawMessage   `json:"item"`
+}
+
+// jsonLDArticleTypes are the schema.org types treated as posts
+var jsonLDArticleTypes = map[string]bool{
+	"BlogPosting":          true,
+	"NewsArticle":          true,
+	"Article":              true,
+	"TechArticle":          true,
+	"ScholarlyArticle":     true,
+	"AnalysisNewsArticle":  true,
+	"ReportageNewsArticle": true,
+}
+
+// jsonLDPosts reads BlogPosting/NewsArticle objects from JSON-LD scripts,
+// including ones nested in @graph, Blog.blogPost and ItemList entries
+func jsonLDPosts(doc *goquery.Document, base *url.URL) []BlogPost {
+	var posts []BlogPost
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		posts = append(posts, jsonLDCollect(json.RawMessage(s.Text()), base, 0)...)
+	})
+
+	return posts
+}
+
+// jsonLDCollect walks a JSON-LD value, which may be an object or an array
+func jsonLDCollect(raw json.RawMessage, base *url.URL, depth int) []BlogPost {
+	if depth > 4 || len(raw) == 0 {
+		return nil
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		var posts []BlogPost
+		for _, elem := range list {
+			posts = append(posts, jsonLDCollect(elem, base, depth+1)...)
+		}
+		return posts
+	}
+
+	var node jsonLD
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return nil
+	}
+
+	var posts []BlogPost
+	if jsonLDIsArticle(node.Type) {
+		post := BlogPost{
+			Title:       cleanText(node.Headline),
+			URL:         resolveURL(base, node.URL),
+			Description: cleanText(node.Description),
+			ImageURL:    resolveURL(base, jsonLDImage(node.Image)),
+		}
+		if post.Title == "" {
+			post.Title = cleanText(node.Name)
+		}
+		post.PublishedAt, _ = parseDate(node.DatePublished)
+		posts = append(posts, post)
+	}
+
+	for _, children := range [][]json.RawMessage{node.Graph, node.BlogPost, node.ItemListElem} {
+		for _, child := range children {
+			posts = append(posts, jsonLDCollect(child, base, depth+1)...)
+		}
+	}
+	posts = append(posts, jsonLDCollect(node.Item, base, depth+1)...)
+
+	return posts
+}
+
+// jsonLDIsArticle reports whether an @type value (string or list) names an article type
+func jsonLDIsArticle(t interface{}) bool {
+	switch v := t.(type) {
+	case string:
+		return jsonLDArticleTypes[v]
+	case []interface{}:
+		for _, elem := range v {
+			if s, ok := elem.(string); ok && jsonLDArticleTypes[s] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonLDImage returns the URL of a schema.org image, which may be a string,
+// an ImageObject or a list of either
+func jsonLDImage(image interface{}) string {
+	switch v := image.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if u, ok := v["url"].(string); ok {
+			return u
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			return jsonLDImage(v[0])
+		}
+	}
+	return ""
+}
+
+// articlePosts reads posts from <article> elements
+func articlePosts(doc *goquery.Document, base *url.URL) []BlogPost {
+	var posts []BlogPost
+
+	doc.Find("article").Each(func(_ int, article *goquery.Selection) {
+		heading := article.Find("h1, h2, h3, h4").First()
+		link := heading.Find("a[href]").First()
+		if link.Length() == 0 {
+			link = article.Find("a[href]").First()
+		}
+
+		title := cleanText(heading.Text())
+		if title == "" {
+			title = cleanText(link.Text())
+		}
+		href, _ := link.Attr("href")
+
+		post := BlogPost{
+			Title:       title,
+			URL:         resolveURL(base, href),
+			Description: cleanText(article.Find("p").First().Text()),
+			PublishedAt: selectionDate(article),
+		}
+		if src, ok := article.Find("img[src]").First().Attr("src"); ok {
+			post.ImageURL = resolveURL(base, src)
+		}
+
+		posts = append(posts, post)
+	})
+
+	return posts
+}
+
+// headingLinkPosts reads posts from h2/h3 headline links, looking for a
+// date in the surrounding list item or container
+func headingLinkPosts(doc *goquery.Document, base *url.URL) []BlogPost {
+	var posts []BlogPost
+
+	doc.Find("h2 a[href], h3 a[href]").Each(func(_ int, link *goquery.Selection) {
+		href, _ := link.Attr("href")
+
+		// Walk up a few levels to find the element that groups the
+		// headline with its date, stopping before a container that also
+		// holds other headlines (and so other posts' dates)
+		var published time.Time
+		container := link.Parent()
+		for level := 0; level < 3 && container.Length() > 0 && published.IsZero(); level++ {
+			if container.Find("h2 a[href], h3 a[href]").Length() > 1 {
+				break
+			}
+			published = selectionDate(container)
+			container = container.Parent()
+		}
+
+		posts = append(posts, BlogPost{
+			Title:       cleanText(link.Text()),
+			URL:         resolveURL(base, href),
+			PublishedAt: published,
+		})
+	})
+
+	return posts
+}
+
+// metaPosts treats the page itself as a post when its OpenGraph, Twitter
+// card or schema.org metadata carry a publish date
+func metaPosts(doc *goquery.Document, base *url.URL) []BlogPost {
+	meta := func(keys ...string) string {
+		for _, key := range keys {
+			sel := doc.Find(`meta[property="` + key + `"], meta[name="` + key + `"], meta[itemprop="` + key + `"]`)
+			if v, ok := sel.First().Attr("content"); ok && strings.TrimSpace(v) != "" {
+				return strings.TrimSpace(v)
+			}
+		}
+		return ""
+	}
+
+	published, ok := parseDate(meta("article:published_time", "datePublished", "date", "pubdate"))
+	if !ok {
+		return nil
+	}
+
+	post := BlogPost{
+		Title:       cleanText(meta("og:title", "twitter:title")),
+		URL:         meta("og:url"),
+		Description: cleanText(meta("og:description", "twitter:description", "description")),
+		PublishedAt: published,
+		ImageURL:    resolveURL(base, meta("og:image", "twitter:image", "twitter:image:src")),
+	}
+	if post.Title == "" {
+		post.Title = cleanText(doc.Find("title").First().Text())
+	}
+	canonical, _ := doc.Find(`link[rel="canonical"]`).First().Attr("href")
+	post.CanonicalURL = resolveURL(base, canonical)
+	if post.URL == "" {
+		post.URL = canonical
+	}
+	if post.URL == "" && base != nil {
+		post.URL = base.String()
+	}
+	post.URL = resolveURL(base, post.URL)
+
+	return []BlogPost{post}
+}
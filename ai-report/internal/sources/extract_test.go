@@ -0,0 +1,179 @@
+package sources
+
+import (
+	"testing"
+	"time"
+)
+
+const blogURL = "https://blog.example.com/posts/"
+
+var june10 = time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+
+// checkPosts compares extracted posts with want, field by field
+func checkPosts(t *testing.T, name string, got, want []BlogPost) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d posts %+v, want %d", name, len(got), got, len(want))
+	}
+	for i := range want {
+		if !got[i].PublishedAt.Equal(want[i].PublishedAt) {
+			t.Errorf("%s: post %d PublishedAt = %v, want %v", name, i, got[i].PublishedAt, want[i].PublishedAt)
+		}
+		g := got[i]
+		g.PublishedAt = want[i].PublishedAt
+		if g != want[i] {
+			t.Errorf("%s: post %d = %+v, want %+v", name, i, g, want[i])
+		}
+	}
+}
+
+func TestJSONLDPosts(t *testing.T) {
+	doc, base := parsePage(t, blogURL, `<html><head>
+		<script type="application/ld+json">{
+			"@context": "https://schema.org",
+			"@graph": [
+				{"@type": "WebSite", "name": "Example Blog", "url": "/"},
+				{"@type": ["BlogPosting", "Article"], "headline": "  Graph post ", "url": "graph-post/",
+				 "datePublished": "2024-06-10T00:00:00Z", "image": {"@type": "ImageObject", "url": "/img/graph.png"}}
+			]
+		}</script>
+		<script type="application/ld+json">{
+			"@type": "ItemList",
+			"itemListElement": [
+				{"@type": "ListItem", "position": 1, "item": {"@type": "NewsArticle", "name": "Listed post",
+				 "url": "https://blog.example.com/listed/", "datePublished": "2024-06-09", "description": "From a list.",
+				 "image": ["https://cdn.example.com/a.png", "https://cdn.example.com/b.png"]}}
+			]
+		}</script>
+		<script type="application/ld+json">{"@type": "Blog", "blogPost": [{"@type": "BlogPosting", "headline": "Nested post", "url": "/nested/"}]}</script>
+		<script type="application/ld+json">not json</script>
+	</head><body></body></html>`)
+
+	checkPosts(t, "jsonLDPosts", jsonLDPosts(doc, base), []BlogPost{
+		{Title: "Graph post", URL: "https://blog.example.com/posts/graph-post/", PublishedAt: june10, ImageURL: "https://blog.example.com/img/graph.png"},
+		{Title: "Listed post", URL: "https://blog.example.com/listed/", Description: "From a list.", PublishedAt: time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC), ImageURL: "https://cdn.example.com/a.png"},
+		{Title: "Nested post", URL: "https://blog.example.com/nested/"},
+	})
+}
+
+func TestArticlePosts(t *testing.T) {
+	doc, base := parsePage(t, blogURL, `<html><body>
+		<article>
+			<img src="../img/cover.png">
+			<h2><a href="first/">First  post</a></h2>
+			<time datetime="2024-06-10">June 10</time>
+			<p>The first paragraph.</p>
+			<p>The second.</p>
+		</article>
+		<article>
+			<h3>Heading without a link</h3>
+			<a href="/second/">Read more</a>
+			<span itemprop="datePublished" content="2024-06-09"></span>
+		</article>
+	</body></html>`)
+
+	checkPosts(t, "articlePosts", articlePosts(doc, base), []BlogPost{
+		{Title: "First post", URL: "https://blog.example.com/posts/first/", Description: "The first paragraph.", PublishedAt: june10, ImageURL: "https://blog.example.com/img/cover.png"},
+		{Title: "Heading without a link", URL: "https://blog.example.com/second/", PublishedAt: time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC)},
+	})
+}
+
+func TestHeadingLinkPosts(t *testing.T) {
+	doc, base := parsePage(t, blogURL, `<html><body>
+		<ul>
+			<li><div class="meta"><h2><a href="/one/">One</a></h2></div><span><time>June 10, 2024</time></span></li>
+			<li><h3><a href="/two/">Two</a></h3></li>
+		</ul>
+		<time>June 1, 2024</time>
+	</body></html>`)
+
+	// Two's list item has no date, and the walk up stops at the list, which
+	// holds One's date as well
+	checkPosts(t, "headingLinkPosts", headingLinkPosts(doc, base), []BlogPost{
+		{Title: "One", URL: "https://blog.example.com/one/", PublishedAt: june10},
+		{Title: "Two", URL: "https://blog.example.com/two/"},
+	})
+}
+
+func TestMetaPosts(t *testing.T) {
+	doc, base := parsePage(t, "https://blog.example.com/posts/meta-post/?utm_source=x", `<html><head>
+		<title>Fallback title</title>
+		<meta property="og:description" content="About the post.">
+		<meta property="article:published_time" content="2024-06-10T00:00:00Z">
+		<meta name="twitter:image" content="/img/card.png">
+		<link rel="canonical" href="/posts/meta-post/">
+	</head><body></body></html>`)
+
+	checkPosts(t, "metaPosts", metaPosts(doc, base), []BlogPost{{
+		Title:        "Fallback title",
+		URL:          "https://blog.example.com/posts/meta-post/",
+		Description:  "About the post.",
+		PublishedAt:  june10,
+		ImageURL:     "https://blog.example.com/img/card.png",
+		CanonicalURL: "https://blog.example.com/posts/meta-post/",
+	}})
+
+	undated, base := parsePage(t, blogURL, `<html><head><meta property="og:title" content="Index"></head></html>`)
+	if posts := metaPosts(undated, base); posts != nil {
+		t.Errorf("metaPosts on a page without a date = %+v, want none", posts)
+	}
+}
+
+func TestExtractBlogPosts(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want []BlogPost
+	}{
+		{
+			"JSON-LD wins over articles",
+			`<script type="application/ld+json">{"@type": "BlogPosting", "headline": "From JSON-LD", "url": "/ld/", "datePublished": "2024-06-10"}</script>
+			<article><h2><a href="/article/">From markup</a></h2><time datetime="2024-06-10"></time></article>`,
+			[]BlogPost{{Title: "From JSON-LD", URL: "https://blog.example.com/ld/", PublishedAt: june10}},
+		},
+		{
+			"undated JSON-LD falls through to articles",
+			`<script type="application/ld+json">{"@type": "BlogPosting", "headline": "No date", "url": "/ld/"}</script>
+			<article><h2><a href="/article/">From markup</a></h2><time datetime="2024-06-10"></time></article>`,
+			[]BlogPost{{Title: "From markup", URL: "https://blog.example.com/article/", PublishedAt: june10}},
+		},
+		{
+			"undated entries are dropped",
+			`<article><h2><a href="/dated/">Dated</a></h2><time datetime="2024-06-10"></time></article>
+			<article><h2><a href="/undated/">Undated</a></h2></article>
+			<article><h2><a href="/dated/">Dated again</a></h2><time datetime="2024-06-10"></time></article>`,
+			[]BlogPost{{Title: "Dated", URL: "https://blog.example.com/dated/", PublishedAt: june10}},
+		},
+		{
+			"no dated posts at all",
+			`<h2><a href="/one/">One</a></h2><h2><a href="/two/">Two</a></h2>`,
+			nil,
+		},
+	}
+
+	source := NewWebScraperSource(WebScraper{Name: "Test", URL: blogURL})
+	for _, tt := range tests {
+		doc, base := parsePage(t, blogURL, "<html><body>"+tt.page+"</body></html>")
+		checkPosts(t, tt.name, source.extractBlogPosts(doc, base), tt.want)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	for _, value := range []string{
+		"2024-06-10T00:00:00Z",
+		"2024-06-10",
+		"Mon, 10 Jun 2024 00:00:00 GMT",
+		"June 10, 2024",
+		"Jun 10, 2024",
+		"10 June 2024",
+		"2024/06/10",
+	} {
+		got, ok := parseDate(value)
+		if !ok || !got.Equal(june10) {
+			t.Errorf("parseDate(%q) = %v, %v, want %v", value, got, ok, june10)
+		}
+	}
+	if _, ok := parseDate("last Tuesday"); ok {
+		t.Error("parseDate parsed an unsupported date")
+	}
+}
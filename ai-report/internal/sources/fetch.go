@@ -0,0 +1,373 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+)
+
+// browserUserAgent is sent by default; some sites turn away anything that
+// does not look like a browser
+const browserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// maxBodySize caps the bytes read from a response
+const maxBodySize = 10 << 20
+
+// httpCacheRetention is how long a cached response is kept after the last
+// run that asked for it
+const httpCacheRetention = 7 * 24 * time.Hour
+
+// ErrOffline is returned in offline mode for URLs the cache does not hold
+var ErrOffline = errors.New("not in the HTTP cache")
+
+// Fetcher is the HTTP layer shared by the sources. It holds each host to
+// its FetchLimits and backs off when a host answers 429 or 503 with a
+// Retry-After. With a cache it makes conditional requests and serves the
+// cached body on 304 Not Modified; offline it serves only from the cache
+// and never touches the network.
+type Fetcher struct {
+	client  *http.Client
+	limits  FetchLimits
+	cache   *HTTPCache
+	offline bool
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// Response is a successful fetch
+type Response struct {
+	// URL is the address the body came from, after redirects
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+
+	// Cached is set when the body was served from the cache
+	Cached bool
+}
+
+// NewFetcher creates a fetcher. A nil cache fetches every URL in full.
+func NewFetcher(limits FetchLimits, cache *HTTPCache, offline bool) *Fetcher {
+	return &Fetcher{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		limits:  limits,
+		cache:   cache,
+		offline: offline,
+		hosts:   make(map[string]*hostState),
+	}
+}
+
+// defaultFetcher serves sources built without a run's fetcher
+var defaultFetcher = NewFetcher(DefaultFetchLimits(), nil, false)
+
+// Get fetches rawURL with the given request headers. Any status other than
+// 200, or 304 for a cached URL, is returned as an HTTPStatusError.
+func (f *Fetcher) Get(ctx context.Context, rawURL string, header http.Header) (*Response, error) {
+	entry, hasEntry := f.cache.entry(rawURL)
+	if f.offline {
+		if hasEntry {
+			if cached, err := f.cache.load(rawURL, entry); err == nil {
+				return cached, nil
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", rawURL, ErrOffline)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", rawURL, err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", browserUserAgent)
+	}
+
+	// Revalidate only when the cached body is there to serve on a 304
+	var cached *Response
+	if hasEntry && (entry.ETag != "" || entry.LastModified != "") {
+		cached, _ = f.cache.load(rawURL, entry)
+	}
+	if cached != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	host := f.host(req.URL.Host)
+	for attempt := 0; ; attempt++ {
+		status, fetched, err := f.do(ctx, host, req)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+			f.cache.store(rawURL, fetched)
+			return fetched, nil
+		case http.StatusNotModified:
+			if cached != nil {
+				return cached, nil
+			}
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			// Hold the host back for every source, and retry once if the
+			// wait is short and fits in the deadline. Otherwise the wait
+			// goes back with the error for the caller's retry policy.
+			if wait, ok := retryAfter(fetched.Header); ok {
+				host.holdOff(wait)
+				if attempt == 0 && wait <= f.limits.MaxRetryAfter && fitsDeadline(ctx, wait) {
+					aggregator.RecordRetry(ctx)
+					continue
+				}
+				return nil, &aggregator.HTTPStatusError{URL: rawURL, StatusCode: status, RetryAfter: wait}
+			}
+		}
+		return nil, &aggregator.HTTPStatusError{URL: rawURL, StatusCode: status}
+	}
+}
+
+// do makes a request within the host's limits. The response body is read
+// only for 200; other responses carry just their headers.
+func (f *Fetcher) do(ctx context.Context, host *hostState, req *http.Request) (int, *Response, error) {
+	release, err := host.acquire(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer release()
+
+	resp, err := f.client.Do(req.Clone(ctx))
+	if err != nil {
+		host.count(0)
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	host.count(resp.StatusCode)
+
+	fetched := &Response{URL: resp.Request.URL, Header: resp.Header}
+	if resp.StatusCode == http.StatusOK {
+		fetched.Body, err = io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to read %s: %w", req.URL, err)
+		}
+	}
+	return resp.StatusCode, fetched, nil
+}
+
+// host returns the state of a host, creating it on first use
+func (f *Fetcher) host(name string) *hostState {
+	name = strings.ToLower(name)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h, ok := f.hosts[name]
+	if !ok {
+		h = newHostState(f.limits.forHost(name))
+		f.hosts[name] = h
+	}
+	return h
+}
+
+// Stats returns the requests made so far, by host
+func (f *Fetcher) Stats() map[string]HostStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stats := make(map[string]HostStats, len(f.hosts))
+	for name, h := range f.hosts {
+		h.mu.Lock()
+		stats[name] = h.stats
+		h.mu.Unlock()
+	}
+	return stats
+}
+
+// fitsDeadline reports whether ctx leaves time to wait d and then make a
+// request
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > d
+}
+
+// HTTPCache keeps the last good response for every URL fetched along with
+// its validators. The index is a JSON file in the cache directory; bodies
+// are stored beside it, one file per URL.
+type HTTPCache struct {
+	dir     string
+	mu      sync.Mutex
+	Entries map[string]CachedResponse `json:"entries"`
+}
+
+// CachedResponse is the index entry for a cached URL. Header is the whole
+// response header, so a body served from the cache reads as it did when
+// fetched; indexes written before it was kept have only the validators.
+type CachedResponse struct {
+	FinalURL     string      `json:"finalUrl,omitempty"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"lastModified,omitempty"`
+	Header       http.Header `json:"header,omitempty"`
+	FetchedAt    time.Time   `json:"fetchedAt"`
+	UsedAt       time.Time   `json:"usedAt"`
+}
+
+// LoadHTTPCache reads the cache kept in dir. A missing index yields an
+// empty cache that Save will create. An unreadable index is reported, but
+// an empty cache bound to dir is still returned so Save replaces it.
+func LoadHTTPCache(dir string) (*HTTPCache, error) {
+	cache := &HTTPCache{dir: dir, Entries: make(map[string]CachedResponse)}
+
+	data, err := os.ReadFile(cache.indexPath())
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return cache, fmt.Errorf("failed to read HTTP cache: %w", err)
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Entries == nil {
+		cache.Entries = make(map[string]CachedResponse)
+		if err != nil {
+			return cache, fmt.Errorf("failed to parse HTTP cache %s: %w", cache.indexPath(), err)
+		}
+	}
+
+	return cache, nil
+}
+
+// Save writes the index back to the cache directory, dropping entries no
+// run has asked for within httpCacheRetention along with their bodies
+func (c *HTTPCache) Save() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	cutoff := time.Now().Add(-httpCacheRetention)
+	for rawURL, entry := range c.Entries {
+		if entry.UsedAt.Before(cutoff) {
+			delete(c.Entries, rawURL)
+			os.Remove(c.bodyPath(rawURL))
+		}
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal HTTP cache: %w", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return os.WriteFile(c.indexPath(), data, 0644)
+}
+
+func (c *HTTPCache) indexPath() string {
+	return filepath.Join(c.dir, "index.json")
+}
+
+// bodyPath names the body file of a URL after its hash
+func (c *HTTPCache) bodyPath(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".body")
+}
+
+// entry returns the index entry for a URL, marking it as used so Save
+// keeps it
+func (c *HTTPCache) entry(rawURL string) (CachedResponse, bool) {
+	if c == nil {
+		return CachedResponse{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.Entries[rawURL]
+	if ok {
+		entry.UsedAt = time.Now().UTC()
+		c.Entries[rawURL] = entry
+	}
+	return entry, ok
+}
+
+// load reads the cached body of a URL along with the header it came with
+func (c *HTTPCache) load(rawURL string, entry CachedResponse) (*Response, error) {
+	body, err := os.ReadFile(c.bodyPath(rawURL))
+	if err != nil {
+		return nil, err
+	}
+	finalURL, err := url.Parse(rawURL)
+	if entry.FinalURL != "" {
+		finalURL, err = url.Parse(entry.FinalURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	header := entry.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if entry.ETag != "" && header.Get("ETag") == "" {
+		header.Set("ETag", entry.ETag)
+	}
+	if entry.LastModified != "" && header.Get("Last-Modified") == "" {
+		header.Set("Last-Modified", entry.LastModified)
+	}
+	return &Response{URL: finalURL, Header: header, Body: body, Cached: true}, nil
+}
+
+// store caches a fetched response. A body that cannot be written leaves
+// the URL uncached rather than failing the fetch.
+func (c *HTTPCache) store(rawURL string, resp *Response) {
+	if c == nil {
+		return
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	// Write then rename, so a concurrent lookup never reads half a body
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.bodyPath(rawURL))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	now := time.Now().UTC()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Entries[rawURL] = CachedResponse{
+		FinalURL:     resp.URL.String(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Header:       resp.Header.Clone(),
+		FetchedAt:    now,
+		UsedAt:       now,
+	}
+}
@@ -0,0 +1,202 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetcherRevalidatesAndReplays(t *testing.T) {
+	var hits, notModified int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("hello"))
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	cache, err := LoadHTTPCache(dir)
+	if err != nil {
+		t.Fatalf("LoadHTTPCache: %v", err)
+	}
+	fetcher := NewFetcher(DefaultFetchLimits(), cache, false)
+
+	first, err := fetcher.Get(context.Background(), srv.URL+"/feed", nil)
+	if err != nil {
+		t.Fatalf("first Get: %v", err)
+	}
+	if string(first.Body) != "hello" || first.Cached {
+		t.Fatalf("first Get = %q cached=%v, want fresh hello", first.Body, first.Cached)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A later run revalidates and is served the cached body on 304
+	cache, err = LoadHTTPCache(dir)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	second, err := NewFetcher(DefaultFetchLimits(), cache, false).Get(context.Background(), srv.URL+"/feed", nil)
+	if err != nil {
+		t.Fatalf("second Get: %v", err)
+	}
+	if string(second.Body) != "hello" || !second.Cached || notModified != 1 {
+		t.Fatalf("second Get = %q cached=%v, 304s=%d; want cached hello after one 304", second.Body, second.Cached, notModified)
+	}
+
+	// Offline replays the cache without a request and fails for the rest
+	offline := NewFetcher(DefaultFetchLimits(), cache, true)
+	third, err := offline.Get(context.Background(), srv.URL+"/feed", nil)
+	if err != nil || string(third.Body) != "hello" {
+		t.Fatalf("offline Get = %v, %v; want cached hello", third, err)
+	}
+	if _, err := offline.Get(context.Background(), srv.URL+"/other", nil); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline Get of uncached URL = %v, want ErrOffline", err)
+	}
+	if hits != 2 {
+		t.Errorf("server hits = %d, want 2", hits)
+	}
+}
+
+func TestFetcherCacheKeepsHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml; charset=iso-8859-1")
+		w.Header().Set("Last-Modified", "Mon, 10 Jun 2024 12:00:00 GMT")
+		w.Header().Add("Link", `</feed?page=2>; rel="next"`)
+		w.Write([]byte("<rss/>"))
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	cache, err := LoadHTTPCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := NewFetcher(DefaultFetchLimits(), cache, false).Get(context.Background(), srv.URL+"/feed", nil)
+	if err != nil {
+		t.Fatalf("first Get: %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both a 304 and an offline replay serve the body with the header it
+	// was fetched with
+	if cache, err = LoadHTTPCache(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, offline := range []bool{false, true} {
+		resp, err := NewFetcher(DefaultFetchLimits(), cache, offline).Get(context.Background(), srv.URL+"/feed", nil)
+		if err != nil {
+			t.Fatalf("offline=%v Get: %v", offline, err)
+		}
+		if !resp.Cached {
+			t.Errorf("offline=%v Get was not served from the cache", offline)
+		}
+		for _, key := range []string{"Content-Type", "Last-Modified", "Link"} {
+			if got, want := resp.Header.Values(key), first.Header.Values(key); strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("offline=%v %s = %q, want %q", offline, key, got, want)
+			}
+		}
+	}
+}
+
+func TestHTTPCacheLoadsOldIndex(t *testing.T) {
+	// An entry written before whole headers were kept still has its
+	// validators restored
+	cache := &HTTPCache{dir: t.TempDir(), Entries: make(map[string]CachedResponse)}
+	u, _ := url.Parse("https://example.com/feed")
+	cache.store(u.String(), &Response{URL: u, Header: http.Header{}, Body: []byte("body")})
+	entry := CachedResponse{ETag: `"v1"`, LastModified: "Mon, 10 Jun 2024 12:00:00 GMT"}
+
+	resp, err := cache.load("https://example.com/feed", entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header.Get("ETag") != `"v1"` || resp.Header.Get("Last-Modified") != entry.LastModified || string(resp.Body) != "body" {
+		t.Errorf("loaded header %v, body %q, want the validators and body", resp.Header, resp.Body)
+	}
+}
+
+func TestFetcherHonoursRetryAfter(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	fetcher := NewFetcher(DefaultFetchLimits(), nil, false)
+	start := time.Now()
+	resp, err := fetcher.Get(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(resp.Body) != "ok" {
+		t.Errorf("body = %q, want ok", resp.Body)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+
+	stats := fetcher.Stats()[strings.TrimPrefix(srv.URL, "http://")]
+	if stats.Requests != 2 || stats.Throttled != 1 {
+		t.Errorf("stats = %+v, want 2 requests with 1 throttled", stats)
+	}
+}
+
+func TestFetcherLimitsConcurrencyPerHost(t *testing.T) {
+	var inFlight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	t.Cleanup(srv.Close)
+
+	fetcher := NewFetcher(FetchLimits{HostLimits: HostLimits{Concurrency: 2}}, nil, false)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := fetcher.Get(context.Background(), fmt.Sprintf("%s/%d", srv.URL, i), nil); err != nil {
+				t.Errorf("Get: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("%d requests in flight at once, want at most 2", peak)
+	}
+}
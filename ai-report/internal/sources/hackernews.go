@@ -1,123 +1,275 @@
 package sources
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"html"
-	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
+
 	"github.com/ai-report/aggregator/internal/aggregator"
 )
 
+// HackerNewsConfig represents a Hacker News source configuration
+type HackerNewsConfig struct {
+	Keywords []string `yaml:"keywords"`
+
+	// Lists are the story lists to read: "top", "new" and/or "best".
+	// Defaults to top.
+	Lists []string `yaml:"lists"`
+
+	// Depth is how many stories to read from each list (default 100)
+	Depth int `yaml:"depth"`
+
+	// Concurrency bounds the number of items fetched at once (default 8)
+	Concurrency int `yaml:"concurrency"`
+
+	// ItemDeadline caps the time spent fetching items, shared by all
+	// workers. Items not fetched by then are counted as failures and the
+	// stories fetched so far are returned. Zero means only the source
+	// timeout applies.
+	ItemDeadline time.Duration `yaml:"itemDeadline"`
+}
+
 // HackerNewsSource implements the Source interface for Hacker News
 type HackerNewsSource struct {
-	keywords []string
-	client   *http.Client
+	config  HackerNewsConfig
+	baseURL string
+	fetcher *Fetcher
+	retry   RetryPolicy
+}
+
+// hnLists maps story list names to their API endpoints
+var hnLists = map[string]string{
+	"top":  "topstories",
+	"new":  "newstories",
+	"best": "beststories",
 }
 
 // NewHackerNewsSource creates a new Hacker News source
-func NewHackerNewsSource(keywords []string) *HackerNewsSource {
+func NewHackerNewsSource(config HackerNewsConfig) *HackerNewsSource {
+	if len(config.Lists) == 0 {
+		config.Lists = []string{"top"}
+	}
+	if config.Depth <= 0 {
+		config.Depth = 100
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 8
+	}
+
 	return &HackerNewsSource{
-		keywords: keywords,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		config:  config,
+		baseURL: "https://hacker-news.firebaseio.com/v0",
+		fetcher: defaultFetcher,
+		retry:   DefaultRetryPolicy("hackernews"),
 	}
 }
 
 // HNItem represents a Hacker News item
 type HNItem struct {
 	ID          int    `json:"id"`
+	Type        string `json:"type"`
 	Title       string `json:"title"`
 	URL         string `json:"url"`
 	Score       int    `json:"score"`
 	Time        int64  `json:"time"`
 	Descendants int    `json:"descendants"`
+	Text        string `json:"text"`
+	Dead        bool   `json:"dead"`
+	Deleted     bool   `json:"deleted"`
 }
 
 // FetchNews fetches news from Hacker News
-func (h *HackerNewsSource) FetchNews() ([]aggregator.RawNewsItem, error) {
-	// Get top stories
-	resp, err := h.client.Get("https://hacker-news.firebaseio.com/v0/topstories.json")
+func (h *HackerNewsSource) FetchNews(ctx context.Context) ([]aggregator.RawNewsItem, error) {
+	storyIDs, err := h.fetchStoryIDs(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch HN top stories: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var storyIDs []int
-	if err := json.NewDecoder(resp.Body).Decode(&storyIDs); err != nil {
-		return nil, fmt.Errorf("failed to decode story IDs: %w", err)
+	stories := h.fetchItems(ctx, storyIDs)
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	// Limit to top 100 stories
-	if len(storyIDs) > 100 {
-		storyIDs = storyIDs[:100]
+	items := make([]aggregator.RawNewsItem, 0)
+	for _, item := range stories {
+		// Check if item matches our keywords
+		if item == nil || !h.matchesKeywords(item) {
+			continue
+		}
+
+		newsItem := aggregator.RawNewsItem{
+			Title:       html.UnescapeString(item.Title),
+			URL:         item.URL,
+			Description: hnText(item.Text),
+			PublishedAt: time.Unix(item.Time, 0),
+			Source:      "Hacker News",
+			Engagement: aggregator.Engagement{
+				Platform: aggregator.PlatformHackerNews,
+				Points:   item.Score,
+				Comments: item.Descendants,
+			},
+		}
+
+		// If no URL, link to HN discussion
+		if newsItem.URL == "" {
+			newsItem.URL = fmt.Sprintf("https://news.ycombinator.com/item?id=%d", item.ID)
+		}
+
+		items = append(items, newsItem)
 	}
 
-	items := make([]aggregator.RawNewsItem, 0)
-	
-	// Fetch each story
-	for _, id := range storyIDs {
-		item, err := h.fetchItem(id)
-		if err != nil {
-			continue // Skip failed items
+	return items, nil
+}
+
+// fetchStoryIDs reads the configured story lists, keeping the first
+// Depth IDs of each and dropping IDs that appear in more than one list
+func (h *HackerNewsSource) fetchStoryIDs(ctx context.Context) ([]int, error) {
+	seen := make(map[int]bool)
+	var ids []int
+
+	for _, list := range h.config.Lists {
+		endpoint, ok := hnLists[list]
+		if !ok {
+			return nil, fmt.Errorf("unknown HN story list %q", list)
 		}
 
-		// Check if item matches our keywords
-		if h.matchesKeywords(item) {
-			newsItem := aggregator.RawNewsItem{
-				Title:       html.UnescapeString(item.Title),
-				URL:         item.URL,
-				Description: fmt.Sprintf("HN Score: %d | Comments: %d", item.Score, item.Descendants),
-				PublishedAt: time.Unix(item.Time, 0),
-				Source:      "Hacker News",
+		var listIDs []int
+		if err := h.getJSON(ctx, fmt.Sprintf("%s/%s.json", h.baseURL, endpoint), &listIDs); err != nil {
+			return nil, fmt.Errorf("failed to fetch HN %s stories: %w", list, err)
+		}
+
+		if len(listIDs) > h.config.Depth {
+			listIDs = listIDs[:h.config.Depth]
+		}
+		for _, id := range listIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
 			}
+		}
+	}
+
+	return ids, nil
+}
+
+// fetchItems fetches stories through a bounded pool of workers. The result
+// is in the same order as ids; entries for failed, dead or non-story items
+// are nil. Failures are reported to the fetch report.
+func (h *HackerNewsSource) fetchItems(ctx context.Context, ids []int) []*HNItem {
+	itemCtx := ctx
+	if h.config.ItemDeadline > 0 {
+		var cancel context.CancelFunc
+		itemCtx, cancel = context.WithTimeout(ctx, h.config.ItemDeadline)
+		defer cancel()
+	}
 
-			// If no URL, link to HN discussion
-			if newsItem.URL == "" {
-				newsItem.URL = fmt.Sprintf("https://news.ycombinator.com/item?id=%d", item.ID)
+	results := make([]*HNItem, len(ids))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < h.config.Concurrency && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				item, err := h.fetchItem(itemCtx, ids[i])
+				if err != nil {
+					aggregator.RecordItemFailure(ctx)
+					continue
+				}
+				if item.Type == "story" && !item.Dead && !item.Deleted {
+					results[i] = item
+				}
 			}
+		}()
+	}
 
-			items = append(items, newsItem)
+	for i := range ids {
+		if itemCtx.Err() != nil {
+			// Deadline hit: count the items never attempted as failures
+			for range ids[i:] {
+				aggregator.RecordItemFailure(ctx)
+			}
+			break
 		}
+		jobs <- i
 	}
+	close(jobs)
+	wg.Wait()
 
-	return items, nil
+	return results
 }
 
 // fetchItem fetches a single HN item
-func (h *HackerNewsSource) fetchItem(id int) (*HNItem, error) {
-	url := fmt.Sprintf("https://hacker-news.firebaseio.com/v0/item/%d.json", id)
-	resp, err := h.client.Get(url)
-	if err != nil {
+func (h *HackerNewsSource) fetchItem(ctx context.Context, id int) (*HNItem, error) {
+	var item *HNItem
+	if err := h.getJSON(ctx, fmt.Sprintf("%s/item/%d.json", h.baseURL, id), &item); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	var item HNItem
-	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
-		return nil, err
+	if item == nil {
+		return nil, fmt.Errorf("HN item %d not found", id)
 	}
+	return item, nil
+}
 
-	return &item, nil
+// getJSON fetches url and decodes its JSON body into v, retrying failed
+// requests
+func (h *HackerNewsSource) getJSON(ctx context.Context, url string, v interface{}) error {
+	return h.retry.Do(ctx, func() error {
+		resp, err := h.fetcher.Get(ctx, url, nil)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(resp.Body, v); err != nil {
+			return &aggregator.ParseError{URL: url, Err: err}
+		}
+		return nil
+	})
+}
+
+// setFetcher replaces the HTTP layer the API is read through
+func (h *HackerNewsSource) setFetcher(f *Fetcher) {
+	h.fetcher = f
+}
+
+// setRetry replaces the policy failed API requests are retried by
+func (h *HackerNewsSource) setRetry(p RetryPolicy) {
+	h.retry = p
+}
+
+// hnText converts the HTML body of a text post (Ask HN, Show HN) to plain
+// text. HN separates paragraphs with bare <p> tags.
+func hnText(body string) string {
+	if body == "" {
+		return ""
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(strings.ReplaceAll(body, "<p>", " <p>")))
+	if err != nil {
+		return ""
+	}
+	return cleanText(doc.Text())
 }
 
 // matchesKeywords checks if an item matches our AI-related keywords
 func (h *HackerNewsSource) matchesKeywords(item *HNItem) bool {
 	titleLower := strings.ToLower(item.Title)
-	
-	for _, keyword := range h.keywords {
+
+	for _, keyword := range h.config.Keywords {
 		if strings.Contains(titleLower, strings.ToLower(keyword)) {
 			return true
 		}
 	}
-	
+
 	return false
 }
 
 // GetName returns the name of the Hacker News source
 func (h *HackerNewsSource) GetName() string {
 	return "Hacker News"
-} 
\ No newline at end of file
+}
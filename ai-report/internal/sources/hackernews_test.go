@@ -0,0 +1,193 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+)
+
+// hnServer is a stand-in for the Hacker News API. Lists maps a list
+// endpoint such as "topstories" to its IDs; every item is an AI story
+// unless overridden, and each item request takes delay. It records the
+// most item requests it saw in flight at once.
+type hnServer struct {
+	*httptest.Server
+
+	lists map[string][]int
+	items map[int]string // raw JSON overriding the default story
+	delay time.Duration
+	slow  map[int]bool // items that answer only when the request is abandoned
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	requested   []int
+}
+
+func newHNServer(t *testing.T, lists map[string][]int) *hnServer {
+	t.Helper()
+
+	hn := &hnServer{lists: lists, items: make(map[int]string), slow: make(map[int]bool)}
+	hn.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v0/")
+		if ids, ok := hn.lists[strings.TrimSuffix(path, ".json")]; ok {
+			fmt.Fprint(w, strings.Join(strings.Fields(fmt.Sprint(ids)), ","))
+			return
+		}
+
+		id, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(path, "item/"), ".json"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		hn.mu.Lock()
+		hn.requested = append(hn.requested, id)
+		hn.inFlight++
+		if hn.inFlight > hn.maxInFlight {
+			hn.maxInFlight = hn.inFlight
+		}
+		body, overridden := hn.items[id]
+		slow := hn.slow[id]
+		hn.mu.Unlock()
+		defer func() {
+			hn.mu.Lock()
+			hn.inFlight--
+			hn.mu.Unlock()
+		}()
+
+		if slow {
+			<-r.Context().Done()
+			return
+		}
+		time.Sleep(hn.delay)
+		if !overridden {
+			body = fmt.Sprintf(`{"id": %d, "type": "story", "title": "AI story %d", "url": "https://example.com/%d", "score": %d, "descendants": 3, "time": 1718000000}`, id, id, id, 10*id)
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(hn.Close)
+	return hn
+}
+
+func newTestHNSource(hn *hnServer, config HackerNewsConfig) *HackerNewsSource {
+	config.Keywords = []string{"ai"}
+	src := NewHackerNewsSource(config)
+	src.baseURL = hn.URL + "/v0"
+	src.fetcher = NewFetcher(FetchLimits{}, nil, false)
+	src.retry = RetryPolicy{Attempts: 1}
+	return src
+}
+
+// fetchHN runs src through an aggregator so item failures are reported
+func fetchHN(t *testing.T, src *HackerNewsSource) ([]aggregator.RawNewsItem, aggregator.SourceReport) {
+	t.Helper()
+	agg := aggregator.New()
+	agg.AddSource(src)
+	items, report, err := agg.FetchAll(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAll: %v", err)
+	}
+	return items, report.Sources[0]
+}
+
+func TestHackerNewsFetchNews(t *testing.T) {
+	hn := newHNServer(t, map[string][]int{
+		"topstories":  {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+		"newstories":  {3, 20, 21, 22, 23, 24, 25, 26, 27, 28},
+		"beststories": {99},
+	})
+	hn.delay = 20 * time.Millisecond
+	hn.items[4] = `{"id": 4, "type": "comment", "text": "AI comment"}`
+	hn.items[5] = `{"id": 5, "type": "story", "title": "Dead AI story", "dead": true}`
+	hn.items[6] = `{"id": 6, "type": "story", "title": "Not about the topic", "url": "https://example.com/6"}`
+	hn.items[7] = `null`
+	hn.items[8] = `{"id": 8, "type": "story", "title": "Ask HN: AI &amp; you", "text": "First<p>Second", "time": 1718000000}`
+	hn.items[21] = `{not json`
+
+	src := newTestHNSource(hn, HackerNewsConfig{Lists: []string{"top", "new"}, Depth: 10, Concurrency: 3})
+	items, report := fetchHN(t, src)
+
+	// Top 10 and new 10, with story 3 in both, make 19 items
+	hn.mu.Lock()
+	requested, maxInFlight := len(hn.requested), hn.maxInFlight
+	hn.mu.Unlock()
+	if requested != 19 {
+		t.Errorf("requested %d items, want 19", requested)
+	}
+	if maxInFlight != 3 {
+		t.Errorf("%d item requests in flight at once, want the concurrency of 3", maxInFlight)
+	}
+
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	want := "AI story 1,AI story 2,AI story 3,Ask HN: AI & you,AI story 9,AI story 10,AI story 20,AI story 22,AI story 23,AI story 24,AI story 25,AI story 26,AI story 27,AI story 28"
+	if got := strings.Join(titles, ","); got != want {
+		t.Errorf("titles = %s\nwant %s", got, want)
+	}
+
+	// The missing item and the one that does not parse are failures; the
+	// comment, the dead story and the off-topic one are not
+	if report.ItemFailures != 2 {
+		t.Errorf("ItemFailures = %d, want 2", report.ItemFailures)
+	}
+
+	ask := items[3]
+	if ask.URL != "https://news.ycombinator.com/item?id=8" || ask.Description != "First Second" {
+		t.Errorf("text post URL %q, description %q, want the discussion and its text", ask.URL, ask.Description)
+	}
+	wantEngagement := aggregator.Engagement{Platform: aggregator.PlatformHackerNews, Points: 10, Comments: 3}
+	if items[0].Engagement != wantEngagement {
+		t.Errorf("Engagement = %+v, want %+v", items[0].Engagement, wantEngagement)
+	}
+}
+
+func TestHackerNewsItemDeadline(t *testing.T) {
+	hn := newHNServer(t, map[string][]int{"topstories": {1, 2, 3, 4, 5, 6}})
+	hn.delay = 10 * time.Millisecond
+	hn.slow[3] = true
+
+	src := newTestHNSource(hn, HackerNewsConfig{Concurrency: 2, ItemDeadline: 300 * time.Millisecond})
+	start := time.Now()
+	items, report := fetchHN(t, src)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("FetchAll took %v, want the slow item cut off at the 300ms item deadline", elapsed)
+	}
+	if report.Status != aggregator.StatusOK {
+		t.Errorf("Status = %s, want ok with the fetched stories", report.Status)
+	}
+	if len(items) != 5 {
+		t.Errorf("got %d items, want the 5 that answered", len(items))
+	}
+	if report.ItemFailures != 1 {
+		t.Errorf("ItemFailures = %d, want the slow item counted", report.ItemFailures)
+	}
+}
+
+func TestHackerNewsItemDeadlineCountsUnattempted(t *testing.T) {
+	hn := newHNServer(t, map[string][]int{"topstories": {1, 2, 3, 4, 5}})
+	hn.slow[1] = true
+
+	// The only worker is stuck on the first item when the deadline passes,
+	// so the other four are never attempted
+	src := newTestHNSource(hn, HackerNewsConfig{Concurrency: 1, ItemDeadline: 100 * time.Millisecond})
+	items, report := fetchHN(t, src)
+
+	if len(items) != 0 {
+		t.Errorf("got %d items, want none", len(items))
+	}
+	if report.ItemFailures != 5 {
+		t.Errorf("ItemFailures = %d, want all 5 counted", report.ItemFailures)
+	}
+}
@@ -0,0 +1,203 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FetchLimits caps the load the fetcher puts on each host. Every host gets
+// the embedded default limits unless Hosts lists its own.
+type FetchLimits struct {
+	HostLimits
+	Hosts map[string]HostLimits
+
+	// MaxRetryAfter is the longest Retry-After a 429 or 503 response may ask
+	// for and still be retried. The host is held back for the full time
+	// either way.
+	MaxRetryAfter time.Duration
+}
+
+// HostLimits caps the requests to one host: how many may be in flight at
+// once and how many may start per second. Zero means no limit.
+type HostLimits struct {
+	Concurrency       int
+	RequestsPerSecond float64
+}
+
+// DefaultFetchLimits allows two requests in flight and two request starts
+// a second per host. The Hacker News API, which serves one request per
+// story, gets more.
+func DefaultFetchLimits() FetchLimits {
+	return FetchLimits{
+		HostLimits: HostLimits{Concurrency: 2, RequestsPerSecond: 2},
+		Hosts: map[string]HostLimits{
+			"hacker-news.firebaseio.com": {Concurrency: 8, RequestsPerSecond: 20},
+		},
+		MaxRetryAfter: time.Minute,
+	}
+}
+
+// Validate reports negative limits
+func (l FetchLimits) Validate() error {
+	if err := l.HostLimits.validate(); err != nil {
+		return err
+	}
+	for host, limits := range l.Hosts {
+		if err := limits.validate(); err != nil {
+			return fmt.Errorf("host %s: %w", host, err)
+		}
+	}
+	if l.MaxRetryAfter < 0 {
+		return fmt.Errorf("maxRetryAfter must not be negative")
+	}
+	return nil
+}
+
+func (l HostLimits) validate() error {
+	if l.Concurrency < 0 || l.RequestsPerSecond < 0 {
+		return fmt.Errorf("fetch limits must not be negative")
+	}
+	return nil
+}
+
+// forHost returns the limits that apply to host
+func (l FetchLimits) forHost(host string) HostLimits {
+	if limits, ok := l.Hosts[host]; ok {
+		return limits
+	}
+	return l.HostLimits
+}
+
+// HostStats counts the requests the fetcher made to one host in a run
+type HostStats struct {
+	Requests    int `json:"requests"`
+	NotModified int `json:"notModified"`
+
+	// Errors counts transport errors and statuses other than 200 and 304,
+	// of which Throttled are the 429 and 503 responses
+	Errors    int `json:"errors"`
+	Throttled int `json:"throttled"`
+
+	// WaitMS is the time requests spent waiting for the host's limits
+	WaitMS int64 `json:"waitMs"`
+}
+
+// hostState enforces the limits of one host and counts its requests
+type hostState struct {
+	slots    chan struct{} // nil when concurrency is unlimited
+	interval time.Duration
+
+	mu    sync.Mutex
+	next  time.Time // earliest start of the next request
+	stats HostStats
+}
+
+func newHostState(limits HostLimits) *hostState {
+	h := &hostState{}
+	if limits.Concurrency > 0 {
+		h.slots = make(chan struct{}, limits.Concurrency)
+	}
+	if limits.RequestsPerSecond > 0 {
+		h.interval = time.Duration(float64(time.Second) / limits.RequestsPerSecond)
+	}
+	return h
+}
+
+// acquire waits for a free slot and the host's next start time. The
+// returned function releases the slot.
+func (h *hostState) acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+	defer func() {
+		h.mu.Lock()
+		h.stats.WaitMS += time.Since(start).Milliseconds()
+		h.mu.Unlock()
+	}()
+
+	release := func() {}
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+			release = func() { <-h.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	h.mu.Lock()
+	now := time.Now()
+	at := h.next
+	if at.Before(now) {
+		at = now
+	}
+	h.next = at.Add(h.interval)
+	h.mu.Unlock()
+
+	if err := sleepContext(ctx, at.Sub(now)); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// slowTo spaces request starts at least interval apart
+func (h *hostState) slowTo(interval time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if interval > h.interval {
+		h.interval = interval
+	}
+}
+
+// holdOff keeps requests from starting for d
+func (h *hostState) holdOff(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if until := time.Now().Add(d); until.After(h.next) {
+		h.next = until
+	}
+}
+
+// count records the outcome of a request: its status, or 0 for a
+// transport error
+func (h *hostState) count(status int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stats.Requests++
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		h.stats.NotModified++
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		h.stats.Throttled++
+		h.stats.Errors++
+	default:
+		h.stats.Errors++
+	}
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP
+// date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
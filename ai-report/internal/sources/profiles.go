@@ -0,0 +1,278 @@
+package sources
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// SelectorProfile tells the scraper where posts live on an index page.
+// Item selects one element per post; the other selectors are evaluated
+// inside it, and a comma-separated list is tried in order, stopping at the
+// first selector that matches. Fields left empty fall back to the Preset,
+// if any, and then to generic heuristics.
+type SelectorProfile struct {
+	Preset string `yaml:"preset"`
+
+	Item    string `yaml:"item"`
+	Title   string `yaml:"title"`
+	Link    string `yaml:"link"`
+	Date    string `yaml:"date"`
+	Summary string `yaml:"summary"`
+	Image   string `yaml:"image"`
+
+	// DateAttr names the attribute holding the date; by default the
+	// datetime and content attributes are tried before the element text.
+	// DateFormat is a Go time layout, or "unix"/"unixms" for epoch values.
+	DateAttr   string `yaml:"dateAttr"`
+	DateFormat string `yaml:"dateFormat"`
+}
+
+// presets are built-in profiles for common blog platforms' default themes
+var presets = map[string]SelectorProfile{
+	"jekyll": {
+		// minima and most themes derived from it
+		Item:       "ul.post-list > li, .post-list > article, article.post",
+		Title:      ".post-link, h2 a, h3 a",
+		Link:       ".post-link, h2 a, h3 a",
+		Date:       ".post-meta time, .post-meta, time",
+		DateFormat: "Jan 2, 2006",
+	},
+	"hugo": {
+		Item:    "article, .post-entry, .post",
+		Title:   ".entry-title, .post-title, h1, h2",
+		Link:    "a.entry-link, h1 a, h2 a, a",
+		Date:    "time, .post-date, .entry-footer span",
+		Summary: ".entry-content, .post-summary, p",
+		Image:   ".entry-cover img, img",
+	},
+	"ghost": {
+		Item:    "article.post-card, article.gh-card",
+		Title:   ".post-card-title, .gh-card-title",
+		Link:    "a.post-card-content-link, a.gh-card-link, a",
+		Date:    "time",
+		Summary: ".post-card-excerpt, .gh-card-excerpt",
+		Image:   "img.post-card-image, .gh-card-image img",
+	},
+	"substack": {
+		Item:    ".post-preview, .portable-archive-list > div",
+		Title:   ".post-preview-title, a[data-testid=post-preview-title]",
+		Link:    "a.post-preview-title, a[data-testid=post-preview-title]",
+		Date:    "time",
+		Summary: ".post-preview-description",
+		Image:   "img",
+	},
+	"wordpress": {
+		Item:    "article.post, article.type-post, .wp-block-post",
+		Title:   ".entry-title, .wp-block-post-title",
+		Link:    ".entry-title a, .wp-block-post-title a",
+		Date:    "time.entry-date, time.published, .wp-block-post-date time",
+		Summary: ".entry-summary, .wp-block-post-excerpt",
+		Image:   "img.wp-post-image, .post-thumbnail img, .wp-block-post-featured-image img",
+	},
+	"quarto": {
+		Item:       ".quarto-post, .quarto-grid-item, .quarto-listing-table tbody tr",
+		Title:      ".listing-title, h3",
+		Link:       "a",
+		Date:       "[data-listing-date-sort]",
+		DateAttr:   "data-listing-date-sort",
+		DateFormat: "unixms",
+		Summary:    ".listing-description",
+		Image:      ".thumbnail img, img",
+	},
+}
+
+// Presets returns the names of the built-in selector profiles
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve merges the profile with its preset and checks every selector.
+// Explicit fields take precedence over the preset's.
+func (p SelectorProfile) resolve() (SelectorProfile, error) {
+	merged := p
+	if p.Preset != "" {
+		preset, ok := presets[p.Preset]
+		if !ok {
+			return merged, fmt.Errorf("unknown selector preset %q (known presets: %v)", p.Preset, Presets())
+		}
+		for _, field := range []struct{ dst, src *string }{
+			{&merged.Item, &preset.Item},
+			{&merged.Title, &preset.Title},
+			{&merged.Link, &preset.Link},
+			{&merged.Date, &preset.Date},
+			{&merged.Summary, &preset.Summary},
+			{&merged.Image, &preset.Image},
+			{&merged.DateAttr, &preset.DateAttr},
+			{&merged.DateFormat, &preset.DateFormat},
+		} {
+			if *field.dst == "" {
+				*field.dst = *field.src
+			}
+		}
+	}
+
+	if merged.Item == "" {
+		return merged, fmt.Errorf("selector profile needs an item selector or a preset")
+	}
+
+	for name, sel := range map[string]string{
+		"item":    merged.Item,
+		"title":   merged.Title,
+		"link":    merged.Link,
+		"date":    merged.Date,
+		"summary": merged.Summary,
+		"image":   merged.Image,
+	} {
+		if sel == "" {
+			continue
+		}
+		if _, err := cascadia.ParseGroup(sel); err != nil {
+			return merged, fmt.Errorf("invalid %s selector %q: %v", name, sel, err)
+		}
+	}
+
+	return merged, nil
+}
+
+// profilePosts extracts posts using a resolved selector profile
+func profilePosts(doc *goquery.Document, base *url.URL, p SelectorProfile) []BlogPost {
+	var posts []BlogPost
+	title, link, date := fallbacks(p.Title), fallbacks(p.Link), fallbacks(p.Date)
+	summary, image := fallbacks(p.Summary), fallbacks(p.Image)
+
+	doc.Find(p.Item).Each(func(_ int, item *goquery.Selection) {
+		titleSel := item.Find("h1, h2, h3, h4").First()
+		if title != nil {
+			titleSel = title.first(item)
+		}
+
+		linkSel := titleSel.Find("a[href]").AddSelection(titleSel.Filter("a[href]")).First()
+		if link != nil {
+			linkSel = link.first(item)
+		}
+		if linkSel.Length() == 0 {
+			linkSel = item.Find("a[href]").First()
+		}
+		href, _ := linkSel.Attr("href")
+
+		post := BlogPost{
+			Title: cleanText(titleSel.Text()),
+			URL:   resolveURL(base, href),
+		}
+		if post.Title == "" {
+			post.Title = cleanText(linkSel.Text())
+		}
+
+		if date != nil {
+			post.PublishedAt = profileDate(date.first(item), p)
+		} else {
+			post.PublishedAt = selectionDate(item)
+		}
+
+		if summary != nil {
+			post.Description = cleanText(summary.first(item).Text())
+		}
+		if image != nil {
+			img := image.first(item)
+			src, ok := img.Attr("src")
+			if !ok || strings.HasPrefix(src, "data:") {
+				src, _ = img.Attr("data-src") // lazy-loaded images
+			}
+			post.ImageURL = resolveURL(base, src)
+		}
+
+		posts = append(posts, post)
+	})
+
+	return posts
+}
+
+// selectorFallbacks are the selectors of a comma-separated field, which
+// are tried in the order written rather than matched as one group
+type selectorFallbacks []cascadia.Selector
+
+// fallbacks compiles a field's selectors, or returns nil for an empty
+// field. The profile has been resolved, so every selector parses.
+func fallbacks(group string) selectorFallbacks {
+	if group == "" {
+		return nil
+	}
+	parsed, err := cascadia.ParseGroup(group)
+	if err != nil {
+		return nil
+	}
+	list := make(selectorFallbacks, len(parsed))
+	for i, sel := range parsed {
+		list[i] = sel.Match
+	}
+	return list
+}
+
+// first returns the first element within or at item that matches the
+// earliest selector to match any
+func (f selectorFallbacks) first(item *goquery.Selection) *goquery.Selection {
+	for _, sel := range f {
+		if match := item.FindMatcher(sel).AddSelection(item.FilterMatcher(sel)).First(); match.Length() > 0 {
+			return match
+		}
+	}
+	return item.Slice(0, 0)
+}
+
+// profileDate reads a date from sel as described by the profile
+func profileDate(sel *goquery.Selection, p SelectorProfile) time.Time {
+	if sel.Length() == 0 {
+		return time.Time{}
+	}
+
+	var candidates []string
+	if p.DateAttr != "" {
+		if v, ok := sel.Attr(p.DateAttr); ok {
+			candidates = append(candidates, v)
+		}
+	} else {
+		for _, attr := range []string{"datetime", "content"} {
+			if v, ok := sel.Attr(attr); ok {
+				candidates = append(candidates, v)
+			}
+		}
+		candidates = append(candidates, cleanText(sel.Text()))
+	}
+
+	for _, value := range candidates {
+		value = strings.TrimSpace(value)
+		switch p.DateFormat {
+		case "unix", "unixms":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			if p.DateFormat == "unixms" {
+				return time.UnixMilli(n).UTC()
+			}
+			return time.Unix(n, 0).UTC()
+		case "":
+		default:
+			if t, err := time.Parse(p.DateFormat, value); err == nil {
+				return t
+			}
+		}
+		if t, ok := parseDate(value); ok {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,168 @@
+package sources
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// parsePage parses an inline HTML page served from pageURL
+func parsePage(t *testing.T, pageURL, page string) (*goquery.Document, *url.URL) {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc, base
+}
+
+// Each page puts a cover image or tag link and a second date ahead of the
+// post's own, so a preset only passes if it tries its selectors in order
+var presetPages = []struct {
+	preset string
+	page   string
+	want   BlogPost
+}{
+	{
+		"jekyll",
+		`<ul class="post-list"><li>
+			<a href="/tags/llm/"><img src="/img/tag.png"></a>
+			<p class="updated">Updated <time datetime="2024-06-12">Jun 12, 2024</time></p>
+			<span class="post-meta">Jun 10, 2024</span>
+			<h3><a class="post-link" href="/2024/06/10/evals.html">Why evals matter</a></h3>
+		</li></ul>`,
+		BlogPost{Title: "Why evals matter", URL: "https://blog.example.com/2024/06/10/evals.html", PublishedAt: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)},
+	},
+	{
+		"hugo",
+		`<article class="post-entry">
+			<figure class="entry-cover"><a href="/img/cover.png"><img src="/img/cover.png"></a></figure>
+			<h1>Blog</h1>
+			<header><h2 class="entry-title">Notes on sparse attention</h2></header>
+			<div class="entry-content"><p>What we learned.</p></div>
+			<footer class="entry-footer"><span>June 10, 2024</span> <time datetime="2024-06-11T00:00:00Z"></time></footer>
+			<a class="entry-link" href="/posts/sparse-attention/"></a>
+		</article>`,
+		BlogPost{Title: "Notes on sparse attention", URL: "https://blog.example.com/posts/sparse-attention/", Description: "What we learned.", PublishedAt: time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC), ImageURL: "https://blog.example.com/img/cover.png"},
+	},
+	{
+		"ghost",
+		`<article class="post-card">
+			<a class="post-card-image-link" href="/tag/research/"><img class="post-card-image" src="/content/images/cover.jpg"></a>
+			<a class="post-card-content-link" href="/scaling-laws/">
+				<h2 class="post-card-title">Scaling laws, revisited</h2>
+				<div class="post-card-excerpt">A second look.</div>
+			</a>
+			<time datetime="2024-06-10">10 Jun 2024</time>
+		</article>`,
+		BlogPost{Title: "Scaling laws, revisited", URL: "https://blog.example.com/scaling-laws/", Description: "A second look.", PublishedAt: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), ImageURL: "https://blog.example.com/content/images/cover.jpg"},
+	},
+	{
+		"substack",
+		`<div class="post-preview">
+			<a href="/p/other"><img src="https://cdn.example.com/cover.png"></a>
+			<a class="post-preview-title" href="/p/agents-in-production">Agents in production</a>
+			<div class="post-preview-description">Field notes.</div>
+			<time datetime="2024-06-10T09:00:00Z">Jun 10</time>
+		</div>`,
+		BlogPost{Title: "Agents in production", URL: "https://blog.example.com/p/agents-in-production", Description: "Field notes.", PublishedAt: time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC), ImageURL: "https://cdn.example.com/cover.png"},
+	},
+	{
+		"wordpress",
+		`<article class="post type-post">
+			<div class="post-thumbnail"><a href="/category/news/"><img src="/wp-content/uploads/cover.jpg"></a></div>
+			<h2 class="entry-title"><a href="/2024/06/10/open-weights/">Open weights, explained</a></h2>
+			<time class="updated" datetime="2024-06-12T00:00:00+00:00"></time>
+			<time class="entry-date published" datetime="2024-06-10T00:00:00+00:00">June 10, 2024</time>
+			<div class="entry-summary"><p>A primer.</p></div>
+		</article>`,
+		BlogPost{Title: "Open weights, explained", URL: "https://blog.example.com/2024/06/10/open-weights/", Description: "A primer.", PublishedAt: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), ImageURL: "https://blog.example.com/wp-content/uploads/cover.jpg"},
+	},
+	{
+		"quarto",
+		`<div class="quarto-post" data-listing-date-sort="1718006400000">
+			<div class="thumbnail"><a href="posts/tokenizers/index.html"><img src="posts/tokenizers/thumb.png"></a></div>
+			<h3>Archive</h3>
+			<h3 class="listing-title">Tokenizers from scratch</h3>
+			<div class="listing-description">Byte pairs.</div>
+		</div>`,
+		BlogPost{Title: "Tokenizers from scratch", URL: "https://blog.example.com/posts/tokenizers/index.html", Description: "Byte pairs.", PublishedAt: time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC), ImageURL: "https://blog.example.com/posts/tokenizers/thumb.png"},
+	},
+}
+
+func TestPresetProfiles(t *testing.T) {
+	for _, tt := range presetPages {
+		profile, err := SelectorProfile{Preset: tt.preset}.resolve()
+		if err != nil {
+			t.Fatalf("%s: resolve: %v", tt.preset, err)
+		}
+		doc, base := parsePage(t, "https://blog.example.com/", "<html><body>"+tt.page+"</body></html>")
+
+		posts := profilePosts(doc, base, profile)
+		if len(posts) != 1 {
+			t.Errorf("%s: got %d posts, want 1", tt.preset, len(posts))
+			continue
+		}
+		got := posts[0]
+		if !got.PublishedAt.Equal(tt.want.PublishedAt) {
+			t.Errorf("%s: PublishedAt = %v, want %v", tt.preset, got.PublishedAt, tt.want.PublishedAt)
+		}
+		got.PublishedAt = tt.want.PublishedAt
+		if got != tt.want {
+			t.Errorf("%s: post = %+v, want %+v", tt.preset, got, tt.want)
+		}
+	}
+}
+
+func TestProfileFallbacksInOrder(t *testing.T) {
+	profile, err := SelectorProfile{
+		Item:  "article",
+		Title: ".headline, h2",
+		Link:  "a.permalink, a",
+		Date:  ".published, time",
+	}.resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, base := parsePage(t, "https://blog.example.com/", `<html><body><article>
+		<h2>Section</h2>
+		<time datetime="2024-06-12T00:00:00Z"></time>
+		<a href="/tags/ai/">ai</a>
+		<p class="headline">The real title</p>
+		<span class="published">2024-06-10</span>
+		<a class="permalink" href="/posts/real/">Read</a>
+	</article></body></html>`)
+
+	posts := profilePosts(doc, base, profile)
+	want := BlogPost{Title: "The real title", URL: "https://blog.example.com/posts/real/", PublishedAt: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)}
+	if len(posts) != 1 || posts[0] != want {
+		t.Errorf("posts = %+v, want %+v", posts, want)
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	if _, err := (SelectorProfile{Preset: "blogger"}).resolve(); err == nil {
+		t.Error("unknown preset resolved")
+	}
+	if _, err := (SelectorProfile{Title: "h2"}).resolve(); err == nil {
+		t.Error("profile without an item selector resolved")
+	}
+	if _, err := (SelectorProfile{Item: "article", Date: "time["}).resolve(); err == nil {
+		t.Error("invalid date selector resolved")
+	}
+
+	p, err := SelectorProfile{Preset: "ghost", Title: "h3"}.resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "h3" || p.Item != presets["ghost"].Item {
+		t.Errorf("resolved %+v, want the explicit title over the preset's", p)
+	}
+}
@@ -0,0 +1,284 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+	"github.com/ai-report/aggregator/internal/clock"
+)
+
+// RedditConfig represents a Reddit source configuration
+type RedditConfig struct {
+	Subreddits []string      `yaml:"subreddits"`
+	Listings   []string      `yaml:"listings"` // "hot" and/or "top"; defaults to both
+	Limit      int           `yaml:"limit"`    // posts per listing, at most 100
+	MaxAge     time.Duration `yaml:"maxAge"`   // drop older posts; defaults to 48h
+}
+
+// RedditSource implements the Source interface for Reddit's public JSON listings
+type RedditSource struct {
+	config  RedditConfig
+	baseURL string
+	fetcher *Fetcher
+	retry   RetryPolicy
+	clock   clock.Clock
+
+	// Rate limit state from the most recent response
+	mu        sync.Mutex
+	remaining float64
+	resetAt   time.Time
+}
+
+// NewRedditSource creates a new Reddit source
+func NewRedditSource(config RedditConfig) *RedditSource {
+	if len(config.Listings) == 0 {
+		config.Listings = []string{"hot", "top"}
+	}
+	if config.Limit <= 0 || config.Limit > 100 {
+		config.Limit = 25
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = 48 * time.Hour
+	}
+
+	return &RedditSource{
+		config:    config,
+		baseURL:   "https://www.reddit.com",
+		remaining: -1,
+		clock:     clock.Real,
+		fetcher:   defaultFetcher,
+		retry:     DefaultRetryPolicy("reddit"),
+	}
+}
+
+// redditUserAgent identifies the aggregator; Reddit throttles generic agents
+const redditUserAgent = "ai-report-aggregator/1.0 (news aggregator)"
+
+// redditListing is the envelope of /r/<sub>/<listing>.json responses
+type redditListing struct {
+	Data struct {
+		Children []struct {
+			Kind string     `json:"kind"`
+			Data redditPost `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+// redditPost holds the fields of a t3 (link) object we use
+type redditPost struct {
+	ID            string  `json:"id"`
+	Title         string  `json:"title"`
+	URL           string  `json:"url"`
+	Permalink     string  `json:"permalink"`
+	Subreddit     string  `json:"subreddit"`
+	Score         int     `json:"score"`
+	NumComments   int     `json:"num_comments"`
+	UpvoteRatio   float64 `json:"upvote_ratio"`
+	Selftext      string  `json:"selftext"`
+	LinkFlairText string  `json:"link_flair_text"`
+	Thumbnail     string  `json:"thumbnail"`
+	CreatedUTC    float64 `json:"created_utc"`
+	Over18        bool    `json:"over_18"`
+	Stickied      bool    `json:"stickied"`
+	IsSelf        bool    `json:"is_self"`
+	Preview       *struct {
+		Images []struct {
+			Source struct {
+				URL string `json:"url"`
+			} `json:"source"`
+		} `json:"images"`
+	} `json:"preview"`
+}
+
+// FetchNews fetches posts from every configured subreddit and listing
+func (r *RedditSource) FetchNews(ctx context.Context) ([]aggregator.RawNewsItem, error) {
+	seen := make(map[string]bool)
+	items := make([]aggregator.RawNewsItem, 0)
+
+	var lastErr error
+	failures := 0
+	for _, sub := range r.config.Subreddits {
+		for _, listing := range r.config.Listings {
+			posts, err := r.fetchListing(ctx, sub, listing)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				lastErr = err
+				failures++
+				continue
+			}
+
+			for _, post := range posts {
+				if seen[post.ID] || post.Over18 || post.Stickied {
+					continue
+				}
+				seen[post.ID] = true
+
+				publishedAt := time.Unix(int64(post.CreatedUTC), 0)
+				if r.clock.Now().Sub(publishedAt) > r.config.MaxAge {
+					continue
+				}
+
+				items = append(items, r.toNewsItem(post, publishedAt))
+			}
+		}
+	}
+
+	if failures > 0 && failures == len(r.config.Subreddits)*len(r.config.Listings) {
+		return nil, lastErr
+	}
+
+	return items, nil
+}
+
+// fetchListing fetches a single listing, waiting out Reddit's rate limit
+// window first if the previous response said we had no requests left
+func (r *RedditSource) fetchListing(ctx context.Context, sub, listing string) ([]redditPost, error) {
+	if err := r.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(r.config.Limit))
+	query.Set("raw_json", "1")
+	if listing == "top" {
+		query.Set("t", "day")
+	}
+	listingURL := fmt.Sprintf("%s/r/%s/%s.json?%s", r.baseURL, url.PathEscape(sub), listing, query.Encode())
+
+	header := http.Header{}
+	header.Set("User-Agent", redditUserAgent)
+
+	var resp *Response
+	err := r.retry.Do(ctx, func() (err error) {
+		resp, err = r.fetcher.Get(ctx, listingURL, header)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch r/%s/%s: %w", sub, listing, err)
+	}
+
+	r.updateRateLimit(resp.Header)
+
+	var body redditListing
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, &aggregator.ParseError{URL: listingURL, Err: err}
+	}
+
+	posts := make([]redditPost, 0, len(body.Data.Children))
+	for _, child := range body.Data.Children {
+		if child.Kind == "t3" {
+			posts = append(posts, child.Data)
+		}
+	}
+
+	return posts, nil
+}
+
+// updateRateLimit records the X-Ratelimit-* headers of a response
+func (r *RedditSource) updateRateLimit(header http.Header) {
+	remaining, err := strconv.ParseFloat(header.Get("X-Ratelimit-Remaining"), 64)
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseFloat(header.Get("X-Ratelimit-Reset"), 64)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remaining = remaining
+	r.resetAt = time.Now().Add(time.Duration(reset * float64(time.Second)))
+}
+
+// waitForRateLimit blocks until the rate limit window resets when the
+// last response reported no remaining requests
+func (r *RedditSource) waitForRateLimit(ctx context.Context) error {
+	r.mu.Lock()
+	exhausted := r.remaining >= 0 && r.remaining < 1
+	wait := time.Until(r.resetAt)
+	r.mu.Unlock()
+
+	if !exhausted || wait <= 0 {
+		return nil
+	}
+	return sleepContext(ctx, wait)
+}
+
+// toNewsItem converts a Reddit post into a RawNewsItem
+func (r *RedditSource) toNewsItem(post redditPost, publishedAt time.Time) aggregator.RawNewsItem {
+	permalink := r.baseURL + post.Permalink
+
+	// Self posts have no external story; link to the discussion
+	link := post.URL
+	if post.IsSelf || link == "" {
+		link = permalink
+	}
+
+	var tags []string
+	if flair := strings.TrimSpace(html.UnescapeString(post.LinkFlairText)); flair != "" {
+		tags = []string{flair}
+	}
+
+	return aggregator.RawNewsItem{
+		Title:       html.UnescapeString(post.Title),
+		URL:         link,
+		Description: cleanText(html.UnescapeString(post.Selftext)),
+		PublishedAt: publishedAt,
+		Source:      "Reddit r/" + post.Subreddit,
+		ImageURL:    redditImage(post),
+		Engagement: aggregator.Engagement{
+			Platform:    aggregator.PlatformReddit,
+			Points:      post.Score,
+			Comments:    post.NumComments,
+			UpvoteRatio: post.UpvoteRatio,
+		},
+		Tags: tags,
+	}
+}
+
+// redditImage returns the best preview image of a post, falling back to
+// the thumbnail. Reddit uses placeholder values like "self" and "default"
+// for posts without one.
+func redditImage(post redditPost) string {
+	if post.Preview != nil && len(post.Preview.Images) > 0 {
+		if src := post.Preview.Images[0].Source.URL; src != "" {
+			return html.UnescapeString(src)
+		}
+	}
+	if strings.HasPrefix(post.Thumbnail, "http") {
+		return post.Thumbnail
+	}
+	return ""
+}
+
+// setClock replaces the clock used for age cutoffs
+func (r *RedditSource) setClock(c clock.Clock) {
+	r.clock = c
+}
+
+// setFetcher replaces the HTTP layer the listings are read through
+func (r *RedditSource) setFetcher(f *Fetcher) {
+	r.fetcher = f
+}
+
+// setRetry replaces the policy failed listing fetches are retried by
+func (r *RedditSource) setRetry(p RetryPolicy) {
+	r.retry = p
+}
+
+// GetName returns the name of the Reddit source
+func (r *RedditSource) GetName() string {
+	return "Reddit"
+}
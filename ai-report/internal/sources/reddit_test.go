@@ -0,0 +1,170 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+)
+
+// newRedditFixtureServer serves testdata/reddit/<listing>.json for any subreddit
+func newRedditFixtureServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handler != nil {
+			handler(w, r)
+		}
+
+		listing := strings.TrimSuffix(filepath.Base(r.URL.Path), ".json")
+		data, err := os.ReadFile(filepath.Join("testdata", "reddit", listing+".json"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestRedditSource(baseURL string) *RedditSource {
+	src := NewRedditSource(RedditConfig{
+		Subreddits: []string{"MachineLearning"},
+		MaxAge:     100 * 365 * 24 * time.Hour, // fixtures are recorded, not live
+	})
+	src.baseURL = baseURL
+	return src
+}
+
+func TestRedditFetchNews(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	srv := newRedditFixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Path+"?"+r.URL.RawQuery)
+		mu.Unlock()
+		if ua := r.Header.Get("User-Agent"); ua != redditUserAgent {
+			t.Errorf("User-Agent = %q, want %q", ua, redditUserAgent)
+		}
+	})
+
+	items, err := newTestRedditSource(srv.URL).FetchNews(context.Background())
+	if err != nil {
+		t.Fatalf("FetchNews: %v", err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("made %d requests, want 2: %v", len(queries), queries)
+	}
+	if !strings.Contains(queries[1], "/r/MachineLearning/top.json") || !strings.Contains(queries[1], "t=day") {
+		t.Errorf("top listing query = %q, want top.json with t=day", queries[1])
+	}
+
+	// Stickied and NSFW posts are dropped, the post in both listings appears once
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	want := []string{
+		"[R] Scaling laws for sparse mixture-of-experts & dense models",
+		"[P] I trained a 1B model on a single GPU",
+		"[N] New open-weights model released",
+	}
+	if strings.Join(titles, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("titles = %q, want %q", titles, want)
+	}
+
+	research := items[0]
+	if research.URL != "https://arxiv.org/abs/2406.01234" {
+		t.Errorf("URL = %q, want the linked paper", research.URL)
+	}
+	if research.Source != "Reddit r/MachineLearning" {
+		t.Errorf("Source = %q", research.Source)
+	}
+	if research.Description != "" {
+		t.Errorf("link post Description = %q, want none", research.Description)
+	}
+	wantEngagement := aggregator.Engagement{
+		Platform:    aggregator.PlatformReddit,
+		Points:      845,
+		Comments:    97,
+		UpvoteRatio: 0.97,
+	}
+	if research.Engagement != wantEngagement {
+		t.Errorf("Engagement = %+v, want %+v", research.Engagement, wantEngagement)
+	}
+	if len(research.Tags) != 1 || research.Tags[0] != "Research" {
+		t.Errorf("Tags = %q, want the Research flair", research.Tags)
+	}
+	if research.ImageURL != "https://external-preview.redd.it/abc002.png?width=1200&format=png&s=deadbeef" {
+		t.Errorf("ImageURL = %q, want unescaped preview source", research.ImageURL)
+	}
+	if !research.PublishedAt.Equal(time.Unix(1718003600, 0)) {
+		t.Errorf("PublishedAt = %v", research.PublishedAt)
+	}
+
+	self := items[1]
+	if self.URL != srv.URL+"/r/MachineLearning/comments/1abc004/p_i_trained_a_1b_model/" {
+		t.Errorf("self post URL = %q, want permalink", self.URL)
+	}
+	if self.ImageURL != "" {
+		t.Errorf("self post ImageURL = %q, want none", self.ImageURL)
+	}
+	if self.Tags != nil {
+		t.Errorf("self post Tags = %q, want none without flair", self.Tags)
+	}
+	if self.Description != "Wrote up how I fit & trained it on one 24GB card. Code is on GitHub." {
+		t.Errorf("self post Description = %q, want the post body", self.Description)
+	}
+
+	if items[2].ImageURL != "https://b.thumbs.redditmedia.com/abc005.jpg" {
+		t.Errorf("ImageURL = %q, want thumbnail fallback", items[2].ImageURL)
+	}
+}
+
+func TestRedditWaitsForRateLimitReset(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	srv := newRedditFixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		w.Header().Set("X-Ratelimit-Remaining", "0.0")
+		w.Header().Set("X-Ratelimit-Reset", "0.3")
+	})
+
+	if _, err := newTestRedditSource(srv.URL).FetchNews(context.Background()); err != nil {
+		t.Fatalf("FetchNews: %v", err)
+	}
+
+	if len(times) != 2 {
+		t.Fatalf("made %d requests, want 2", len(times))
+	}
+	if gap := times[1].Sub(times[0]); gap < 250*time.Millisecond {
+		t.Errorf("second request sent after %v, want it to wait for the reset window", gap)
+	}
+}
+
+func TestRedditHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, err := newTestRedditSource(srv.URL).FetchNews(context.Background())
+
+	var statusErr *aggregator.HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("err = %v, want HTTP 403 status error", err)
+	}
+}
@@ -0,0 +1,248 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+	"github.com/ai-report/aggregator/internal/clock"
+	"github.com/ai-report/aggregator/internal/config"
+)
+
+// Env carries state shared by all sources built for a run
+type Env struct {
+	// Feeds caches the feeds discovered for scraped sites
+	Feeds *FeedCache
+
+	// Clock is the time sources filter and date items against
+	Clock clock.Clock
+
+	// HTTP is the fetcher every source makes its requests through
+	HTTP *Fetcher
+
+	// Robots vets the URLs scraped sites are read from against their
+	// robots.txt
+	Robots *Robots
+
+	// Retry holds the retry policy of each source type that does not use
+	// its default
+	Retry map[string]RetryPolicy
+}
+
+// clocked is implemented by sources whose output depends on the current time
+type clocked interface {
+	setClock(clock.Clock)
+}
+
+// fetching is implemented by sources that make HTTP requests
+type fetching interface {
+	setFetcher(*Fetcher)
+}
+
+// retrying is implemented by sources that retry failed requests
+type retrying interface {
+	setRetry(RetryPolicy)
+}
+
+// Factory builds a source from its configuration entry
+type Factory func(spec *config.SourceSpec, env *Env) (aggregator.Source, error)
+
+var registry = map[string]Factory{}
+
+// Register makes a source type available to Build under the given name
+func Register(sourceType string, factory Factory) {
+	if _, exists := registry[sourceType]; exists {
+		panic(fmt.Sprintf("sources: type %q registered twice", sourceType))
+	}
+	registry[sourceType] = factory
+}
+
+// Types returns the registered source types in sorted order
+func Types() []string {
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Build creates the source described by spec using the registered factory.
+// A nil env builds the source with private, in-memory state.
+func Build(spec *config.SourceSpec, env *Env) (aggregator.Source, error) {
+	factory, ok := registry[spec.Type]
+	if !ok {
+		return nil, spec.Errorf("unknown source type %q (known types: %v)", spec.Type, Types())
+	}
+	if env == nil {
+		env = &Env{}
+	}
+	if env.Feeds == nil {
+		env.Feeds = NewFeedCache()
+	}
+	if env.Clock == nil {
+		env.Clock = clock.Real
+	}
+	if env.HTTP == nil {
+		env.HTTP = defaultFetcher
+	}
+	if env.Robots == nil {
+		env.Robots = NewRobots(DefaultBot(), env.HTTP)
+	}
+
+	source, err := factory(spec, env)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := source.(clocked); ok {
+		c.setClock(env.Clock)
+	}
+	if f, ok := source.(fetching); ok {
+		f.setFetcher(env.HTTP)
+	}
+	if policy, ok := env.Retry[spec.Type]; ok {
+		if r, ok := source.(retrying); ok {
+			r.setRetry(policy)
+		}
+	}
+
+	if spec.Category != "" {
+		category, err := aggregator.ParseCategory(spec.Category)
+		if err != nil {
+			return nil, spec.Errorf("%v", err)
+		}
+		source = categorized{Source: source, category: category}
+	}
+	return source, nil
+}
+
+// categorized tags every item of a source with the source's configured
+// category
+type categorized struct {
+	aggregator.Source
+	category aggregator.Category
+}
+
+func (c categorized) FetchNews(ctx context.Context) ([]aggregator.RawNewsItem, error) {
+	items, err := c.Source.FetchNews(ctx)
+	for i := range items {
+		if items[i].SourceCategory == "" {
+			items[i].SourceCategory = c.category
+		}
+	}
+	return items, err
+}
+
+func init() {
+	Register("rss", func(spec *config.SourceSpec, env *Env) (aggregator.Source, error) {
+		var feed RSSFeed
+		if err := spec.Decode(&feed); err != nil {
+			return nil, err
+		}
+		if err := requireName(spec, feed.Name); err != nil {
+			return nil, err
+		}
+		if err := requireURL(spec, feed.URL); err != nil {
+			return nil, err
+		}
+		return NewRSSSource(feed), nil
+	})
+
+	Register("scraper", func(spec *config.SourceSpec, env *Env) (aggregator.Source, error) {
+		var site WebScraper
+		if err := spec.Decode(&site); err != nil {
+			return nil, err
+		}
+		if err := requireName(spec, site.Name); err != nil {
+			return nil, err
+		}
+		if err := requireURL(spec, site.URL); err != nil {
+			return nil, err
+		}
+		if site.Selectors != nil {
+			if _, err := site.Selectors.resolve(); err != nil {
+				return nil, spec.Errorf("%v", err)
+			}
+		}
+		source := NewWebScraperSource(site)
+		source.feeds = env.Feeds
+		source.robots = env.Robots
+		return source, nil
+	})
+
+	Register("hackernews", func(spec *config.SourceSpec, env *Env) (aggregator.Source, error) {
+		var cfg HackerNewsConfig
+		if err := spec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		if len(cfg.Keywords) == 0 {
+			return nil, spec.Errorf("hackernews source needs at least one keyword")
+		}
+		for _, list := range cfg.Lists {
+			if _, ok := hnLists[list]; !ok {
+				return nil, spec.Errorf("hackernews list %q must be top, new or best", list)
+			}
+		}
+		if cfg.Depth > 500 {
+			return nil, spec.Errorf("hackernews depth %d exceeds the 500 stories the API returns", cfg.Depth)
+		}
+		return NewHackerNewsSource(cfg), nil
+	})
+
+	Register("reddit", func(spec *config.SourceSpec, env *Env) (aggregator.Source, error) {
+		var cfg RedditConfig
+		if err := spec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		if len(cfg.Subreddits) == 0 {
+			return nil, spec.Errorf("reddit source needs at least one subreddit")
+		}
+		for _, listing := range cfg.Listings {
+			if listing != "hot" && listing != "top" {
+				return nil, spec.Errorf("reddit listing %q must be hot or top", listing)
+			}
+		}
+		return NewRedditSource(cfg), nil
+	})
+
+	Register("twitter", func(spec *config.SourceSpec, env *Env) (aggregator.Source, error) {
+		var account TwitterAccount
+		if err := spec.Decode(&account); err != nil {
+			return nil, err
+		}
+		if account.Handle == "" {
+			return nil, spec.Errorf("twitter source is missing a handle")
+		}
+		if err := requireURL(spec, account.URL); err != nil {
+			return nil, err
+		}
+		for _, mirror := range account.Mirrors {
+			if err := requireURL(spec, mirror); err != nil {
+				return nil, err
+			}
+		}
+		return NewTwitterSource(account), nil
+	})
+}
+
+// requireName checks that a source has a display name
+func requireName(spec *config.SourceSpec, name string) error {
+	if name == "" {
+		return spec.Errorf("%s source is missing a name", spec.Type)
+	}
+	return nil
+}
+
+// requireURL checks that a source URL is an absolute http(s) URL
+func requireURL(spec *config.SourceSpec, rawURL string) error {
+	if rawURL == "" {
+		return spec.Errorf("%s source is missing a url", spec.Type)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return spec.Errorf("%s source has invalid url %q", spec.Type, rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package sources
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ai-report/aggregator/internal/config"
+)
+
+func TestBuildReportsBadEntries(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown type", "  - type: atom\n    name: Feed\n", `config.yaml:3: unknown source type "atom"`},
+		{"unknown key", "  - type: rss\n    name: Feed\n    url: https://example.com/feed\n    limt: 5\n", `config.yaml:6: unknown option "limt" for rss source`},
+		{"missing name", "  - type: rss\n    url: https://example.com/feed\n", "config.yaml:3: rss source is missing a name"},
+		{"missing url", "  - type: scraper\n    name: Site\n", "config.yaml:3: scraper source is missing a url"},
+		{"relative url", "  - type: rss\n    name: Feed\n    url: /feed\n", `config.yaml:3: rss source has invalid url "/feed"`},
+		{"twitter mirror", "  - type: twitter\n    handle: someone\n    url: https://x.com/someone\n    mirrors: [ftp://mirror]\n", `config.yaml:3: twitter source has invalid url "ftp://mirror"`},
+		{"unknown category", "  - type: rss\n    name: Feed\n    url: https://example.com/feed\n    category: gossip\n", `config.yaml:3: unknown category "gossip"`},
+	}
+
+	for _, tt := range tests {
+		cfg, err := config.Parse("config.yaml", []byte("timeout: 30s\nsources:\n"+tt.yaml))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		_, err = Build(&cfg.Sources[0], nil)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%s: Build error = %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+)
+
+// RetryPolicy retries requests that fail for reasons likely to pass:
+// timeouts, temporary DNS and network failures, 5xx and 429 responses.
+// Client errors, parse errors and skipped fetches are returned at once.
+// Waits grow exponentially from BaseDelay up to MaxDelay, with jitter, and
+// no wait outlasts the context's deadline. A response's Retry-After is
+// waited out in full, or not retried if it is longer than MaxDelay.
+type RetryPolicy struct {
+	// Attempts is the number of tries in total; 1 means no retries
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetryPolicy returns the policy sources of a type start with:
+// three attempts a second or two apart. Twitter makes one attempt per
+// Nitter instance, as it fails over to the next instance instead.
+func DefaultRetryPolicy(sourceType string) RetryPolicy {
+	policy := RetryPolicy{Attempts: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	switch sourceType {
+	case "hackernews":
+		policy.BaseDelay = 500 * time.Millisecond
+	case "twitter":
+		policy.Attempts = 1
+	}
+	return policy
+}
+
+// Validate reports a policy Do cannot follow
+func (p RetryPolicy) Validate() error {
+	if p.Attempts < 1 {
+		return fmt.Errorf("attempts must be at least 1")
+	}
+	if p.BaseDelay < 0 || p.MaxDelay < 0 {
+		return fmt.Errorf("delays must not be negative")
+	}
+	return nil
+}
+
+// Do calls fn until it succeeds, fails with an error not worth retrying or
+// runs out of attempts, and returns its last error. Each retry is recorded
+// in the fetch report.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= p.Attempts || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+
+		wait := p.backoff(attempt)
+		var statusErr *aggregator.HTTPStatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			if p.MaxDelay > 0 && statusErr.RetryAfter > p.MaxDelay {
+				return err
+			}
+			if statusErr.RetryAfter > wait {
+				wait = statusErr.RetryAfter
+			}
+		}
+		if !fitsDeadline(ctx, wait) {
+			return err
+		}
+		if sleepContext(ctx, wait) != nil {
+			return err
+		}
+		aggregator.RecordRetry(ctx)
+	}
+}
+
+// backoff returns the wait before retry n (from 1): BaseDelay doubled for
+// each earlier retry, capped at MaxDelay, and jittered to between half and
+// all of that so sources failing together do not retry together
+func (p RetryPolicy) backoff(n int) time.Duration {
+	wait := p.BaseDelay
+	for i := 1; i < n && (p.MaxDelay <= 0 || wait < p.MaxDelay); i++ {
+		wait *= 2
+	}
+	if p.MaxDelay > 0 && wait > p.MaxDelay {
+		wait = p.MaxDelay
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// errorKind is the cause of a failed request as far as retrying goes
+type errorKind string
+
+const (
+	kindDNS       errorKind = "dns"
+	kindTimeout   errorKind = "timeout"
+	kindServer    errorKind = "5xx"
+	kindThrottled errorKind = "429"
+	kindClient    errorKind = "4xx"
+	kindParse     errorKind = "parse"
+	kindNetwork   errorKind = "network"
+	kindOther     errorKind = "other"
+)
+
+// classify returns the kind of a request error
+func classify(err error) errorKind {
+	var dnsErr *net.DNSError
+	var statusErr *aggregator.HTTPStatusError
+	var parseErr *aggregator.ParseError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return kindOther
+	case errors.Is(err, context.DeadlineExceeded):
+		return kindTimeout
+	case errors.As(err, &dnsErr):
+		return kindDNS
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.StatusCode == http.StatusTooManyRequests:
+			return kindThrottled
+		case statusErr.StatusCode >= 500:
+			return kindServer
+		default:
+			return kindClient
+		}
+	case errors.As(err, &parseErr), errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return kindParse
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return kindTimeout
+		}
+		return kindNetwork
+	default:
+		return kindOther
+	}
+}
+
+// retryable reports whether a request that failed with err may succeed if
+// made again. A host that does not exist will not exist a second later.
+func retryable(err error) bool {
+	switch classify(err) {
+	case kindTimeout, kindServer, kindThrottled, kindNetwork:
+		return true
+	case kindDNS:
+		var dnsErr *net.DNSError
+		errors.As(err, &dnsErr)
+		return !dnsErr.IsNotFound
+	default:
+		return false
+	}
+}
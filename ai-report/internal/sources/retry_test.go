@@ -0,0 +1,189 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+)
+
+func TestClassifyRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		kind      errorKind
+		retryable bool
+	}{
+		{"unknown host", &url.Error{Op: "Get", URL: "https://nope.invalid", Err: &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}}, kindDNS, false},
+		{"dns timeout", &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}, kindDNS, true},
+		{"deadline", fmt.Errorf("fetch: %w", context.DeadlineExceeded), kindTimeout, true},
+		{"cancelled", context.Canceled, kindOther, false},
+		{"503", &aggregator.HTTPStatusError{StatusCode: http.StatusServiceUnavailable}, kindServer, true},
+		{"429", &aggregator.HTTPStatusError{StatusCode: http.StatusTooManyRequests}, kindThrottled, true},
+		{"404", fmt.Errorf("page: %w", &aggregator.HTTPStatusError{StatusCode: http.StatusNotFound}), kindClient, false},
+		{"parse", &aggregator.ParseError{Err: errors.New("bad xml")}, kindParse, false},
+		{"json", &json.SyntaxError{}, kindParse, false},
+		{"refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, kindNetwork, true},
+		{"offline", fmt.Errorf("x: %w", ErrOffline), kindOther, false},
+		{"skipped", &aggregator.SkippedError{Reason: "disallowed"}, kindOther, false},
+	}
+
+	for _, tt := range tests {
+		if got := classify(tt.err); got != tt.kind {
+			t.Errorf("%s: classify = %s, want %s", tt.name, got, tt.kind)
+		}
+		if got := retryable(tt.err); got != tt.retryable {
+			t.Errorf("%s: retryable = %v, want %v", tt.name, got, tt.retryable)
+		}
+	}
+}
+
+func TestRetryPolicyRetriesServerErrors(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) < 3 {
+			http.Error(w, "try again", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	fetcher := NewFetcher(FetchLimits{}, nil, false)
+	policy := RetryPolicy{Attempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: 20 * time.Millisecond}
+
+	var resp *Response
+	err := policy.Do(context.Background(), func() (err error) {
+		resp, err = fetcher.Get(context.Background(), srv.URL, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if string(resp.Body) != "ok" || hits != 3 {
+		t.Errorf("body %q after %d requests, want ok after 3", resp.Body, hits)
+	}
+}
+
+func TestRetryPolicyGivesUpOnClientErrors(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	fetcher := NewFetcher(FetchLimits{}, nil, false)
+	policy := RetryPolicy{Attempts: 5, BaseDelay: 10 * time.Millisecond}
+
+	err := policy.Do(context.Background(), func() error {
+		_, err := fetcher.Get(context.Background(), srv.URL, nil)
+		return err
+	})
+	var statusErr *aggregator.HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("err = %v, want HTTP 404", err)
+	}
+	if hits != 1 {
+		t.Errorf("made %d requests, want 1", hits)
+	}
+}
+
+func TestRetryPolicyRespectsDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	policy := RetryPolicy{Attempts: 3, BaseDelay: time.Second}
+	calls := 0
+	start := time.Now()
+	err := policy.Do(ctx, func() error {
+		calls++
+		return &aggregator.HTTPStatusError{StatusCode: http.StatusServiceUnavailable}
+	})
+
+	if err == nil || calls != 1 {
+		t.Errorf("Do = %v after %d calls, want the error after 1", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Do took %v, want no wait that outlasts the deadline", elapsed)
+	}
+}
+
+func TestRetryPolicyHonoursLongRetryAfter(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	fetcher := NewFetcher(DefaultFetchLimits(), nil, false)
+	start := time.Now()
+	err := DefaultRetryPolicy("rss").Do(ctx, func() error {
+		_, err := fetcher.Get(ctx, srv.URL, nil)
+		return err
+	})
+
+	var statusErr *aggregator.HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want HTTP 429", err)
+	}
+	if hits != 1 {
+		t.Errorf("made %d requests, want 1", hits)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Do took %v, want no wait for an hour-long Retry-After", elapsed)
+	}
+}
+
+func TestRetryPolicyWaitsOutShortRetryAfter(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) < 3 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	fetcher := NewFetcher(FetchLimits{MaxRetryAfter: time.Minute}, nil, false)
+	policy := RetryPolicy{Attempts: 2, BaseDelay: 10 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+	start := time.Now()
+	err := policy.Do(context.Background(), func() error {
+		_, err := fetcher.Get(context.Background(), srv.URL, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 1900*time.Millisecond {
+		t.Errorf("Do took %v, want both 1s Retry-After waits", elapsed)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{Attempts: 6, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for n, ceiling := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		for i := 0; i < 20; i++ {
+			if wait := policy.backoff(n + 1); wait < ceiling/2 || wait > ceiling {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", n+1, wait, ceiling/2, ceiling)
+			}
+		}
+	}
+}
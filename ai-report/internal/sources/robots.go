@@ -0,0 +1,289 @@
+package sources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+)
+
+// Bot identifies the scraper to the sites it visits. UserAgent is sent as
+// "UserAgent (+Contact)" and its product token, the part before "/", is
+// what robots.txt groups are matched against.
+type Bot struct {
+	UserAgent string
+	Contact   string
+}
+
+// DefaultBot is the identity the scraper crawls under unless configured
+func DefaultBot() Bot {
+	return Bot{
+		UserAgent: "AIReportBot/1.0",
+		Contact:   "https://ai-report.com",
+	}
+}
+
+// Validate reports a bot without a name or a usable contact URL
+func (b Bot) Validate() error {
+	if b.token() == "" {
+		return fmt.Errorf("userAgent must not be empty")
+	}
+	u, err := url.Parse(b.Contact)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("contact must be an http(s) URL, got %q", b.Contact)
+	}
+	return nil
+}
+
+// String returns the User-Agent header value
+func (b Bot) String() string {
+	return b.UserAgent + " (+" + b.Contact + ")"
+}
+
+// token returns the product token robots.txt groups name the bot by
+func (b Bot) token() string {
+	token, _, _ := strings.Cut(strings.TrimSpace(b.UserAgent), "/")
+	return strings.ToLower(token)
+}
+
+// header returns request headers that identify the bot
+func (b Bot) header() http.Header {
+	header := http.Header{}
+	header.Set("User-Agent", b.String())
+	return header
+}
+
+// Robots answers whether the bot may fetch a URL. The robots.txt of each
+// host is fetched once per run through the shared fetcher, which caches
+// it between runs; its Crawl-delay slows the fetcher down for that host.
+type Robots struct {
+	bot     Bot
+	fetcher *Fetcher
+
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+// robotsEntry is the robots.txt of one host, fetched on first use
+type robotsEntry struct {
+	mu    sync.Mutex
+	done  bool
+	rules robotsRules
+}
+
+// NewRobots creates a robots.txt checker for bot
+func NewRobots(bot Bot, fetcher *Fetcher) *Robots {
+	return &Robots{
+		bot:     bot,
+		fetcher: fetcher,
+		hosts:   make(map[string]*robotsEntry),
+	}
+}
+
+// defaultRobots serves sources built without a run's checker
+var defaultRobots = NewRobots(DefaultBot(), defaultFetcher)
+
+// Check returns nil if the bot may fetch rawURL and a SkippedError if
+// robots.txt disallows it. A robots.txt that cannot be fetched for a
+// reason other than an HTTP status is returned as an error.
+func (r *Robots) Check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid URL %q", rawURL)
+	}
+
+	rules, err := r.rules(ctx, u)
+	if err != nil {
+		return err
+	}
+	if rules.unavailable != "" {
+		return &aggregator.SkippedError{URL: rawURL, Reason: rules.unavailable}
+	}
+	if !rules.allowed(u) {
+		return &aggregator.SkippedError{URL: rawURL, Reason: "disallowed by robots.txt for " + r.bot.UserAgent}
+	}
+	return nil
+}
+
+// rules returns the rules of u's host, fetching its robots.txt on first
+// use. Per RFC 9309 a 4xx response other than 429 allows everything and
+// any other status disallows everything for the run.
+func (r *Robots) rules(ctx context.Context, u *url.URL) (robotsRules, error) {
+	site := strings.ToLower(u.Scheme + "://" + u.Host)
+
+	r.mu.Lock()
+	entry, ok := r.hosts[site]
+	if !ok {
+		entry = &robotsEntry{}
+		r.hosts[site] = entry
+	}
+	r.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.done {
+		return entry.rules, nil
+	}
+
+	resp, err := r.fetcher.Get(ctx, site+"/robots.txt", r.bot.header())
+	var statusErr *aggregator.HTTPStatusError
+	switch {
+	case err == nil:
+		entry.rules = parseRobots(resp.Body, r.bot.token())
+	case errors.As(err, &statusErr) && statusErr.StatusCode >= 400 && statusErr.StatusCode < 500 && statusErr.StatusCode != http.StatusTooManyRequests:
+		entry.rules = robotsRules{}
+	case errors.As(err, &statusErr):
+		entry.rules = robotsRules{unavailable: fmt.Sprintf("robots.txt returned HTTP %d", statusErr.StatusCode)}
+	default:
+		return robotsRules{}, fmt.Errorf("failed to fetch robots.txt for %s: %w", site, err)
+	}
+	entry.done = true
+
+	if entry.rules.crawlDelay > 0 {
+		r.fetcher.host(u.Host).slowTo(entry.rules.crawlDelay)
+	}
+	return entry.rules, nil
+}
+
+// robotsRules are the rules of a robots.txt that apply to one bot
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+
+	// unavailable is set, to the reason, when robots.txt could not be read
+	// and every URL is disallowed
+	unavailable string
+}
+
+// robotsRule is an Allow or Disallow line. Patterns may use "*" for any
+// run of characters and end in "$" to match the end of the path.
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// parseRobots reads the groups of a robots.txt that name token, or the "*"
+// groups if none do. Several groups naming the bot are merged.
+func parseRobots(data []byte, token string) robotsRules {
+	var matched, wildcard robotsRules
+	var agents []string
+	inRules, named := false, false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			// A user-agent line after rules starts a new group
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agent := strings.ToLower(value)
+			agents = append(agents, agent)
+			named = named || agent == token
+			continue
+		}
+		if key != "allow" && key != "disallow" && key != "crawl-delay" {
+			continue
+		}
+		inRules = true
+
+		for _, agent := range agents {
+			var group *robotsRules
+			switch {
+			case agent == token:
+				group = &matched
+			case agent == "*":
+				group = &wildcard
+			default:
+				continue
+			}
+
+			switch key {
+			case "crawl-delay":
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					group.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			default:
+				// An empty Disallow allows everything and adds nothing
+				if value != "" {
+					group.rules = append(group.rules, robotsRule{allow: key == "allow", pattern: value})
+				}
+			}
+		}
+	}
+
+	if named {
+		return matched
+	}
+	return wildcard
+}
+
+// allowed reports whether the rules let the bot fetch u. The longest
+// matching pattern decides, and Allow wins a tie.
+func (r robotsRules) allowed(u *url.URL) bool {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+
+	allow, best := true, -1
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || (n == best && rule.allow) {
+			allow, best = rule.allow, n
+		}
+	}
+	return allow
+}
+
+// robotsMatch reports whether a robots.txt path pattern matches path
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	if len(parts) == 1 {
+		return !anchored || rest == ""
+	}
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	last := parts[len(parts)-1]
+	if anchored {
+		return strings.HasSuffix(rest, last)
+	}
+	return strings.Contains(rest, last)
+}
@@ -0,0 +1,204 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+)
+
+const testRobots = `# Everyone else stays out
+User-agent: *
+Disallow: /
+
+User-agent: OtherBot
+User-agent: AIReportBot
+Disallow: /private/
+Allow: /private/open$
+Disallow: /*.pdf$
+Disallow: /search?
+
+User-agent: LateBot
+Disallow: /late/
+`
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		token string
+		path  string
+		want  bool
+	}{
+		{"aireportbot", "/blog/", true},
+		{"aireportbot", "/private/", false},
+		{"aireportbot", "/private/notes", false},
+		{"aireportbot", "/private/open", true},
+		{"aireportbot", "/private/open/more", false},
+		{"aireportbot", "/papers/scaling.pdf", false},
+		{"aireportbot", "/papers/scaling.pdf?dl=1", true},
+		{"aireportbot", "/search?q=ai", false},
+		{"aireportbot", "/robots.txt", true},
+		{"otherbot", "/private/notes", false},
+		{"latebot", "/blog/", true},
+		{"latebot", "/late/post", false},
+		{"unknownbot", "/blog/", false},
+		{"unknownbot", "/robots.txt", true},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse("https://example.com" + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := parseRobots([]byte(testRobots), tt.token).allowed(u); got != tt.want {
+			t.Errorf("%s %s allowed = %v, want %v", tt.token, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRobotsCrawlDelay(t *testing.T) {
+	rules := parseRobots([]byte("User-agent: AIReportBot\nCrawl-delay: 2.5\nDisallow:\n"), "aireportbot")
+	if rules.crawlDelay != 2500*time.Millisecond {
+		t.Errorf("crawlDelay = %v, want 2.5s", rules.crawlDelay)
+	}
+	if len(rules.rules) != 0 {
+		t.Errorf("rules = %v, want an empty Disallow to add none", rules.rules)
+	}
+}
+
+// robotsSite is a stand-in site serving robots as its robots.txt, or
+// answering it with status when robots is empty. It records the pages
+// requested and when.
+type robotsSite struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	robotsHits int
+	pages      []string
+	pageTimes  []time.Time
+	userAgents []string
+}
+
+func newRobotsSite(t *testing.T, robots string, status int) *robotsSite {
+	t.Helper()
+
+	site := &robotsSite{}
+	site.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site.mu.Lock()
+		defer site.mu.Unlock()
+		site.userAgents = append(site.userAgents, r.UserAgent())
+
+		if r.URL.Path == "/robots.txt" {
+			site.robotsHits++
+			if robots == "" {
+				w.WriteHeader(status)
+				return
+			}
+			w.Write([]byte(robots))
+			return
+		}
+		site.pages = append(site.pages, r.URL.Path)
+		site.pageTimes = append(site.pageTimes, time.Now())
+		w.Write([]byte("<html><body><p>No posts yet</p></body></html>"))
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+// newRobotsScraper builds a scraper for path on site that reads through
+// fetcher and robots
+func newRobotsScraper(site *robotsSite, path string, fetcher *Fetcher, robots *Robots) *WebScraperSource {
+	source := NewWebScraperSource(WebScraper{Name: "Test " + path, URL: site.URL + path, SkipDiscovery: true})
+	source.fetcher = fetcher
+	source.robots = robots
+	return source
+}
+
+func TestScraperSkipsDisallowedPages(t *testing.T) {
+	site := newRobotsSite(t, testRobots, 0)
+	fetcher := NewFetcher(FetchLimits{}, nil, false)
+	robots := NewRobots(DefaultBot(), fetcher)
+
+	agg := aggregator.New()
+	agg.AddSource(newRobotsScraper(site, "/private/blog", fetcher, robots))
+	agg.AddSource(newRobotsScraper(site, "/blog/", fetcher, robots))
+
+	_, report, err := agg.FetchAll(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAll: %v", err)
+	}
+	if report.OK != 1 || report.Skipped != 1 || report.Failed != 0 {
+		t.Fatalf("report ok=%d skipped=%d failed=%d, want 1, 1, 0", report.OK, report.Skipped, report.Failed)
+	}
+	for _, sr := range report.Sources {
+		if sr.Source == "Test /private/blog" {
+			if sr.Status != aggregator.StatusSkipped || !strings.Contains(sr.Error, "disallowed by robots.txt") {
+				t.Errorf("private page reported %s: %q, want skipped as disallowed", sr.Status, sr.Error)
+			}
+		}
+	}
+
+	site.mu.Lock()
+	defer site.mu.Unlock()
+	if site.robotsHits != 1 {
+		t.Errorf("robots.txt fetched %d times, want once per host", site.robotsHits)
+	}
+	if len(site.pages) != 1 || site.pages[0] != "/blog/" {
+		t.Errorf("pages fetched = %v, want only /blog/", site.pages)
+	}
+	for _, ua := range site.userAgents {
+		if ua != DefaultBot().String() {
+			t.Errorf("User-Agent = %q, want %q", ua, DefaultBot().String())
+		}
+	}
+}
+
+func TestScraperHonoursCrawlDelay(t *testing.T) {
+	site := newRobotsSite(t, "User-agent: *\nCrawl-delay: 1\n", 0)
+	fetcher := NewFetcher(FetchLimits{}, nil, false)
+	robots := NewRobots(DefaultBot(), fetcher)
+
+	for _, path := range []string{"/a/", "/b/"} {
+		if _, err := newRobotsScraper(site, path, fetcher, robots).FetchNews(context.Background()); err != nil {
+			t.Fatalf("FetchNews %s: %v", path, err)
+		}
+	}
+
+	site.mu.Lock()
+	defer site.mu.Unlock()
+	if len(site.pageTimes) != 2 {
+		t.Fatalf("fetched %d pages, want 2", len(site.pageTimes))
+	}
+	if gap := site.pageTimes[1].Sub(site.pageTimes[0]); gap < 900*time.Millisecond {
+		t.Errorf("pages fetched %v apart, want the 1s crawl delay", gap)
+	}
+}
+
+func TestRobotsStatus(t *testing.T) {
+	tests := []struct {
+		status  int
+		skipped bool
+	}{
+		{http.StatusNotFound, false},
+		{http.StatusForbidden, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		site := newRobotsSite(t, "", tt.status)
+		robots := NewRobots(DefaultBot(), NewFetcher(FetchLimits{}, nil, false))
+
+		err := robots.Check(context.Background(), site.URL+"/blog/")
+		var skipped *aggregator.SkippedError
+		if got := errors.As(err, &skipped); got != tt.skipped || (!got && err != nil) {
+			t.Errorf("robots.txt %d: Check = %v, want skipped=%v", tt.status, err, tt.skipped)
+		}
+	}
+}
@@ -1,81 +1,76 @@
 package sources
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"html"
 	"net/http"
 	"time"
 
 	"github.com/ai-report/aggregator/internal/aggregator"
+	"github.com/ai-report/aggregator/internal/clock"
 	"github.com/mmcdole/gofeed"
 )
 
 // RSSFeed represents an RSS feed configuration
 type RSSFeed struct {
-	Name     string
-	URL      string
-	Category string
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
 }
 
 // RSSSource implements the Source interface for RSS feeds
 type RSSSource struct {
-	feed   RSSFeed
-	parser *gofeed.Parser
+	feed    RSSFeed
+	parser  *gofeed.Parser
+	fetcher *Fetcher
+	header  http.Header // request headers, nil for the defaults
+	retry   RetryPolicy
+	clock   clock.Clock
 }
 
 // NewRSSSource creates a new RSS source
 func NewRSSSource(feed RSSFeed) *RSSSource {
-	// Create a custom HTTP client with user agent
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:       10,
-			IdleConnTimeout:    30 * time.Second,
-			DisableCompression: false,
-		},
-	}
-
-	// Create parser with custom client
-	fp := gofeed.NewParser()
-	fp.Client = httpClient
-	// Use a browser-like user agent to avoid bot detection
-	fp.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
-
 	return &RSSSource{
-		feed:   feed,
-		parser: fp,
+		feed:    feed,
+		parser:  gofeed.NewParser(),
+		fetcher: defaultFetcher,
+		retry:   DefaultRetryPolicy("rss"),
+		clock:   clock.Real,
 	}
 }
 
 // FetchNews fetches news from the RSS feed
-func (r *RSSSource) FetchNews() ([]aggregator.RawNewsItem, error) {
+func (r *RSSSource) FetchNews(ctx context.Context) ([]aggregator.RawNewsItem, error) {
 	var feed *gofeed.Feed
-	var err error
-
-	// Retry logic with exponential backoff
-	maxRetries := 3
-	for i := 0; i < maxRetries; i++ {
-		feed, err = r.parser.ParseURL(r.feed.URL)
-		if err == nil {
-			break
-		}
-
-		// If it's not the last retry, wait before retrying
-		if i < maxRetries-1 {
-			waitTime := time.Duration(1<<uint(i)) * time.Second // 1s, 2s, 4s
-			time.Sleep(waitTime)
-		}
+	err := r.retry.Do(ctx, func() (err error) {
+		feed, err = fetchFeed(ctx, r.fetcher, r.parser, r.feed.URL, r.header)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch RSS feed %s: %w", r.feed.Name, err)
 	}
 
+	return r.toNewsItems(feed), nil
+}
+
+// fetchOnce fetches and converts the feed with a single attempt. It is used
+// to probe candidate feed URLs, where retrying a 404 would only waste time.
+func (r *RSSSource) fetchOnce(ctx context.Context) ([]aggregator.RawNewsItem, error) {
+	feed, err := fetchFeed(ctx, r.fetcher, r.parser, r.feed.URL, r.header)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse RSS feed %s after %d retries: %w", r.feed.Name, maxRetries, err)
+		return nil, err
 	}
+	return r.toNewsItems(feed), nil
+}
 
+// toNewsItems converts the recent items of a parsed feed into news items
+func (r *RSSSource) toNewsItems(feed *gofeed.Feed) []aggregator.RawNewsItem {
 	items := make([]aggregator.RawNewsItem, 0, len(feed.Items))
 
 	for _, item := range feed.Items {
 		// Parse published date
-		publishedAt := time.Now()
+		publishedAt := r.clock.Now()
 		if item.PublishedParsed != nil {
 			publishedAt = *item.PublishedParsed
 		} else if item.UpdatedParsed != nil {
@@ -83,7 +78,7 @@ func (r *RSSSource) FetchNews() ([]aggregator.RawNewsItem, error) {
 		}
 
 		// Skip items older than 48 hours
-		if time.Since(publishedAt) > 48*time.Hour {
+		if r.clock.Now().Sub(publishedAt) > 48*time.Hour {
 			continue
 		}
 
@@ -112,10 +107,52 @@ func (r *RSSSource) FetchNews() ([]aggregator.RawNewsItem, error) {
 		items = append(items, newsItem)
 	}
 
-	return items, nil
+	return items
+}
+
+// setClock replaces the clock used for age cutoffs
+func (r *RSSSource) setClock(c clock.Clock) {
+	r.clock = c
+}
+
+// setFetcher replaces the HTTP layer the feed is read through
+func (r *RSSSource) setFetcher(f *Fetcher) {
+	r.fetcher = f
+}
+
+// setRetry replaces the policy failed fetches are retried by
+func (r *RSSSource) setRetry(p RetryPolicy) {
+	r.retry = p
 }
 
 // GetName returns the name of the RSS source
 func (r *RSSSource) GetName() string {
 	return r.feed.Name
 }
+
+// fetchFeed downloads and parses a feed. A body gofeed cannot read is
+// returned as a ParseError.
+func fetchFeed(ctx context.Context, fetcher *Fetcher, parser *gofeed.Parser, feedURL string, header http.Header) (*gofeed.Feed, error) {
+	resp, err := fetcher.Get(ctx, feedURL, header)
+	if err != nil {
+		return nil, err
+	}
+	feed, err := parser.Parse(bytes.NewReader(resp.Body))
+	if err != nil {
+		return nil, &aggregator.ParseError{URL: feedURL, Err: err}
+	}
+	return feed, nil
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,262 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-report/aggregator/internal/aggregator"
+	"github.com/ai-report/aggregator/internal/clock"
+	"github.com/mmcdole/gofeed"
+	xhtml "golang.org/x/net/html"
+)
+
+// TwitterAccount configuration
+type TwitterAccount struct {
+	Handle string `yaml:"handle"`
+	URL    string `yaml:"url"`
+
+	// Mirrors are Nitter instance base URLs (e.g. https://nitter.poast.org)
+	// tried in order when URL fails
+	Mirrors []string `yaml:"mirrors"`
+
+	SkipRetweets bool `yaml:"skipRetweets"`
+	SkipReplies  bool `yaml:"skipReplies"`
+}
+
+// TwitterSource implements the Source interface for Twitter/X accounts
+// through Nitter RSS feeds
+type TwitterSource struct {
+	account TwitterAccount
+	parser  *gofeed.Parser
+	fetcher *Fetcher
+	retry   RetryPolicy
+	clock   clock.Clock
+
+	// Index into instances() of the last instance that worked, so the next
+	// fetch starts there instead of at a known-bad instance
+	mu        sync.Mutex
+	preferred int
+}
+
+// NewTwitterSource creates a new Twitter source
+func NewTwitterSource(account TwitterAccount) *TwitterSource {
+	return &TwitterSource{
+		account: account,
+		parser:  gofeed.NewParser(),
+		fetcher: defaultFetcher,
+		retry:   DefaultRetryPolicy("twitter"),
+		clock:   clock.Real,
+	}
+}
+
+// Nitter prefixes retweets with "RT by @user:" and replies with "R to @user:"
+var (
+	retweetPrefix = regexp.MustCompile(`^RT by @\w+: `)
+	replyPrefix   = regexp.MustCompile(`^R to @\w+: `)
+)
+
+// FetchNews fetches recent tweets, failing over across Nitter instances
+func (t *TwitterSource) FetchNews(ctx context.Context) ([]aggregator.RawNewsItem, error) {
+	instances := t.instances()
+
+	t.mu.Lock()
+	start := t.preferred
+	t.mu.Unlock()
+
+	var lastErr error
+	for n := 0; n < len(instances); n++ {
+		i := (start + n) % len(instances)
+		if n > 0 {
+			aggregator.RecordRetry(ctx)
+		}
+
+		var feed *gofeed.Feed
+		err := t.retry.Do(ctx, func() (err error) {
+			feed, err = fetchFeed(ctx, t.fetcher, t.parser, instances[i], nil)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+
+		t.mu.Lock()
+		t.preferred = i
+		t.mu.Unlock()
+
+		return t.toNewsItems(feed), nil
+	}
+
+	return nil, fmt.Errorf("all %d Nitter instances failed for @%s: %w", len(instances), t.account.Handle, lastErr)
+}
+
+// instances returns the feed URLs to try: the configured URL followed by
+// the same feed path on every mirror
+func (t *TwitterSource) instances() []string {
+	urls := []string{t.account.URL}
+	for _, mirror := range t.account.Mirrors {
+		urls = append(urls, strings.TrimSuffix(mirror, "/")+"/"+t.account.Handle+"/rss")
+	}
+	return urls
+}
+
+// toNewsItems converts a Nitter feed into news items
+func (t *TwitterSource) toNewsItems(feed *gofeed.Feed) []aggregator.RawNewsItem {
+	items := make([]aggregator.RawNewsItem, 0, len(feed.Items))
+
+	for _, item := range feed.Items {
+		title := html.UnescapeString(item.Title)
+		if retweetPrefix.MatchString(title) {
+			if t.account.SkipRetweets {
+				continue
+			}
+			title = retweetPrefix.ReplaceAllString(title, "")
+		}
+		if t.account.SkipReplies && replyPrefix.MatchString(title) {
+			continue
+		}
+
+		publishedAt := t.clock.Now()
+		if item.PublishedParsed != nil {
+			publishedAt = *item.PublishedParsed
+		}
+		if t.clock.Now().Sub(publishedAt) > 48*time.Hour {
+			continue
+		}
+
+		link, image := tweetLinks(item.Description, item.Link)
+		if link == "" {
+			link = tweetPermalink(item.Link)
+		}
+
+		items = append(items, aggregator.RawNewsItem{
+			Title:       title,
+			URL:         link,
+			Description: title,
+			PublishedAt: publishedAt,
+			Source:      t.GetName(),
+			Author:      tweetAuthor(item, t.account.Handle),
+			ImageURL:    image,
+		})
+	}
+
+	return items
+}
+
+// tweetLinks returns the first external link and the first image in a
+// tweet's HTML body. Links back to the Nitter instance (mentions, hashtags,
+// quoted tweets) and to Twitter/X itself are not story links.
+func tweetLinks(body, permalink string) (link, image string) {
+	doc, err := xhtml.Parse(strings.NewReader(body))
+	if err != nil {
+		return "", ""
+	}
+
+	instanceHost := ""
+	if u, err := url.Parse(permalink); err == nil {
+		instanceHost = u.Host
+	}
+
+	var walk func(n *xhtml.Node)
+	walk = func(n *xhtml.Node) {
+		if n.Type == xhtml.ElementNode {
+			switch n.Data {
+			case "a":
+				if href := attr(n, "href"); link == "" && isStoryLink(href, instanceHost) {
+					link = href
+				}
+			case "img":
+				if src := attr(n, "src"); image == "" && src != "" {
+					image = src
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return link, image
+}
+
+// isStoryLink reports whether href points away from Nitter and Twitter
+func isStoryLink(href, instanceHost string) bool {
+	u, err := url.Parse(href)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	switch host {
+	case instanceHost, "twitter.com", "x.com", "mobile.twitter.com", "t.co":
+		return false
+	}
+	return true
+}
+
+// tweetPermalink rewrites a Nitter status URL to its x.com equivalent
+func tweetPermalink(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Path == "" {
+		return link
+	}
+	return "https://x.com" + u.Path
+}
+
+// tweetAuthor returns the tweet author, which differs from the account
+// for retweets. Nitter puts the "@handle" in dc:creator, which gofeed's
+// name/address parsing does not always keep, so read it directly.
+func tweetAuthor(item *gofeed.Item, handle string) string {
+	if dc := item.DublinCoreExt; dc != nil && len(dc.Creator) > 0 && dc.Creator[0] != "" {
+		return strings.TrimPrefix(dc.Creator[0], "@")
+	}
+	if item.Author != nil && item.Author.Name != "" {
+		return strings.TrimPrefix(item.Author.Name, "@")
+	}
+	for _, author := range item.Authors {
+		if author != nil && author.Name != "" {
+			return strings.TrimPrefix(author.Name, "@")
+		}
+	}
+	return handle
+}
+
+// attr returns the value of the named attribute of n
+func attr(n *xhtml.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// setClock replaces the clock used for age cutoffs
+func (t *TwitterSource) setClock(c clock.Clock) {
+	t.clock = c
+}
+
+// setFetcher replaces the HTTP layer the feeds are read through
+func (t *TwitterSource) setFetcher(f *Fetcher) {
+	t.fetcher = f
+}
+
+// setRetry replaces the policy each instance's feed is retried by
+func (t *TwitterSource) setRetry(p RetryPolicy) {
+	t.retry = p
+}
+
+// GetName returns the name of the Twitter source
+func (t *TwitterSource) GetName() string {
+	return fmt.Sprintf("Twitter/@%s", t.account.Handle)
+}
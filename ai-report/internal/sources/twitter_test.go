@@ -0,0 +1,165 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// nitterFeed renders a Nitter-style RSS feed for @OpenAI served from host
+func nitterFeed(host string) string {
+	pub := time.Now().Add(-time.Hour).UTC().Format(time.RFC1123Z)
+
+	item := func(title, creator, body, id string) string {
+		return fmt.Sprintf(`<item>
+  <title>%s</title>
+  <dc:creator>%s</dc:creator>
+  <description><![CDATA[%s]]></description>
+  <pubDate>%s</pubDate>
+  <link>%s/OpenAI/status/%s#m</link>
+</item>`, title, creator, body, pub, host, id)
+	}
+
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel>
+<title>OpenAI / @OpenAI</title>
+<link>` + host + `/OpenAI</link>
+` + item("Introducing our newest model",
+		"@OpenAI",
+		`<p>Introducing our newest model <a href="`+host+`/search?q=%23AI">#AI</a> <a href="https://openai.com/index/new-model/">openai.com/index/new-model/</a></p><img src="`+host+`/pic/media%2Fabc.jpg" />`,
+		"1001") + `
+` + item("RT by @OpenAI: Our paper is out",
+		"@OpenAIResearch",
+		`<p>Our paper is out <a href="https://arxiv.org/abs/2501.00001">arxiv.org/abs/2501.00001</a></p>`,
+		"1002") + `
+` + item("R to @someone: thanks!",
+		"@OpenAI",
+		`<p>thanks! <a href="`+host+`/someone">@someone</a></p>`,
+		"1003") + `
+</channel>
+</rss>`
+}
+
+// newNitterServer starts a stand-in Nitter instance. A failing instance
+// answers every request with 503.
+func newNitterServer(t *testing.T, failing bool, hits *int32) *httptest.Server {
+	t.Helper()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		if failing {
+			http.Error(w, "instance has been rate limited", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/OpenAI/rss" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, nitterFeed(srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTwitterFailsOverToMirror(t *testing.T) {
+	var badHits, goodHits int32
+	bad := newNitterServer(t, true, &badHits)
+	good := newNitterServer(t, false, &goodHits)
+
+	src := NewTwitterSource(TwitterAccount{
+		Handle:  "OpenAI",
+		URL:     bad.URL + "/OpenAI/rss",
+		Mirrors: []string{good.URL + "/"},
+	})
+
+	items, err := src.FetchNews(context.Background())
+	if err != nil {
+		t.Fatalf("FetchNews: %v", err)
+	}
+	if badHits != 1 || goodHits != 1 {
+		t.Fatalf("hits bad=%d good=%d, want 1 each", badHits, goodHits)
+	}
+	if len(items) != 3 {
+		t.Fatalf("got %d items, want 3", len(items))
+	}
+
+	first := items[0]
+	if first.URL != "https://openai.com/index/new-model/" {
+		t.Errorf("URL = %q, want first external link", first.URL)
+	}
+	if first.Author != "OpenAI" {
+		t.Errorf("Author = %q, want OpenAI", first.Author)
+	}
+	if first.ImageURL != good.URL+"/pic/media%2Fabc.jpg" {
+		t.Errorf("ImageURL = %q", first.ImageURL)
+	}
+	if first.Source != "Twitter/@OpenAI" {
+		t.Errorf("Source = %q", first.Source)
+	}
+
+	retweet := items[1]
+	if retweet.Title != "Our paper is out" || retweet.Author != "OpenAIResearch" {
+		t.Errorf("retweet = %q by %q, want prefix stripped and original author", retweet.Title, retweet.Author)
+	}
+
+	// A reply with no external link falls back to the x.com permalink
+	if items[2].URL != "https://x.com/OpenAI/status/1003" {
+		t.Errorf("reply URL = %q, want x.com permalink", items[2].URL)
+	}
+
+	// The next fetch starts at the mirror that worked
+	if _, err := src.FetchNews(context.Background()); err != nil {
+		t.Fatalf("second FetchNews: %v", err)
+	}
+	if badHits != 1 || goodHits != 2 {
+		t.Errorf("after second fetch hits bad=%d good=%d, want 1 and 2", badHits, goodHits)
+	}
+}
+
+func TestTwitterSkipsRetweetsAndReplies(t *testing.T) {
+	var hits int32
+	srv := newNitterServer(t, false, &hits)
+
+	src := NewTwitterSource(TwitterAccount{
+		Handle:       "OpenAI",
+		URL:          srv.URL + "/OpenAI/rss",
+		SkipRetweets: true,
+		SkipReplies:  true,
+	})
+
+	items, err := src.FetchNews(context.Background())
+	if err != nil {
+		t.Fatalf("FetchNews: %v", err)
+	}
+	if len(items) != 1 || items[0].Title != "Introducing our newest model" {
+		t.Fatalf("items = %+v, want only the original tweet", items)
+	}
+}
+
+func TestTwitterAllInstancesFail(t *testing.T) {
+	var hits int32
+	first := newNitterServer(t, true, &hits)
+	second := newNitterServer(t, true, &hits)
+
+	src := NewTwitterSource(TwitterAccount{
+		Handle:  "OpenAI",
+		URL:     first.URL + "/OpenAI/rss",
+		Mirrors: []string{second.URL},
+	})
+
+	_, err := src.FetchNews(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "all 2 Nitter instances failed") {
+		t.Fatalf("err = %v, want all instances failed", err)
+	}
+	if hits != 2 {
+		t.Errorf("hits = %d, want 2", hits)
+	}
+}
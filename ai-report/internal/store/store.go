@@ -0,0 +1,207 @@
+// Package store keeps a record of every item the aggregator has seen,
+// across runs, in an embedded bbolt database keyed by canonical URL
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// maxScores caps the score history kept per item, oldest dropped first
+const maxScores = 48
+
+var itemsBucket = []byte("items")
+
+// Record is what the store knows about an item
+type Record struct {
+	URL       string       `json:"url"`
+	Title     string       `json:"title"`
+	FirstSeen time.Time    `json:"firstSeen"`
+	LastSeen  time.Time    `json:"lastSeen"`
+	Sources   []string     `json:"sources"`
+	Scores    []ScorePoint `json:"scores,omitempty"`
+}
+
+// ScorePoint is an item's score in one run
+type ScorePoint struct {
+	At    time.Time `json:"at"`
+	Score float64   `json:"score"`
+}
+
+// Sighting is an item seen in a run, as passed to Record
+type Sighting struct {
+	URL    string
+	Title  string
+	Source string
+	Score  float64
+}
+
+// Store is an item database opened for a run
+type Store struct {
+	db       *bolt.DB
+	readOnly bool
+}
+
+// Open opens or creates the store at path
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open item store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(itemsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise item store %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// OpenReadOnly opens an existing store for lookups only; Record and Prune
+// do nothing. Replays use it so they see history without adding to it.
+func OpenReadOnly(path string) (*Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open item store %s: %w", path, err)
+	}
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open item store %s: %w", path, err)
+	}
+	return &Store{db: db, readOnly: true}, nil
+}
+
+// Close releases the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Lookup returns the records held for the given URLs. URLs the store has
+// not seen are left out.
+func (s *Store) Lookup(urls []string) (map[string]Record, error) {
+	records := make(map[string]Record)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(itemsBucket)
+		if bucket == nil {
+			return nil
+		}
+		for _, url := range urls {
+			data := bucket.Get([]byte(url))
+			if data == nil {
+				continue
+			}
+			var rec Record
+			if err := json.Unmarshal(data, &rec); err != nil {
+				return fmt.Errorf("corrupt record for %s: %w", url, err)
+			}
+			records[url] = rec
+		}
+		return nil
+	})
+	return records, err
+}
+
+// Record notes that the sighted items were seen at now: new items get a
+// first-seen time, every item's last-seen time, sources and score history
+// are updated. Sightings of the same URL in one run keep the best score.
+func (s *Store) Record(sightings []Sighting, now time.Time) error {
+	if s.readOnly {
+		return nil
+	}
+	now = now.UTC()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(itemsBucket)
+		updated := make(map[string]*Record)
+
+		for _, sighting := range sightings {
+			if sighting.URL == "" {
+				continue
+			}
+
+			rec, ok := updated[sighting.URL]
+			if !ok {
+				rec = &Record{URL: sighting.URL, FirstSeen: now}
+				if data := bucket.Get([]byte(sighting.URL)); data != nil {
+					if err := json.Unmarshal(data, rec); err != nil {
+						return fmt.Errorf("corrupt record for %s: %w", sighting.URL, err)
+					}
+				}
+				rec.LastSeen = now
+				rec.Scores = append(rec.Scores, ScorePoint{At: now, Score: sighting.Score})
+				updated[sighting.URL] = rec
+			} else if last := &rec.Scores[len(rec.Scores)-1]; sighting.Score > last.Score {
+				last.Score = sighting.Score
+			}
+
+			rec.Title = sighting.Title
+			if !contains(rec.Sources, sighting.Source) {
+				rec.Sources = append(rec.Sources, sighting.Source)
+			}
+		}
+
+		for url, rec := range updated {
+			if len(rec.Scores) > maxScores {
+				rec.Scores = rec.Scores[len(rec.Scores)-maxScores:]
+			}
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(url), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Prune deletes items last seen before cutoff and returns how many it
+// removed
+func (s *Store) Prune(cutoff time.Time) (int, error) {
+	if s.readOnly {
+		return 0, nil
+	}
+
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(itemsBucket)
+		var stale [][]byte
+		err := bucket.ForEach(func(key, data []byte) error {
+			var rec Record
+			if err := json.Unmarshal(data, &rec); err != nil || rec.LastSeen.Before(cutoff) {
+				stale = append(stale, append([]byte(nil), key...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range stale {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		removed = len(stale)
+		return nil
+	})
+	return removed, err
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	firstRun  = time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	secondRun = firstRun.Add(time.Hour)
+)
+
+func openTestStore(t *testing.T, path string) *Store {
+	t.Helper()
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func lookup(t *testing.T, s *Store, urls ...string) map[string]Record {
+	t.Helper()
+	records, err := s.Lookup(urls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestRecordAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "items.db")
+
+	s := openTestStore(t, path)
+	err := s.Record([]Sighting{
+		{URL: "https://example.com/a", Title: "A", Source: "Feed", Score: 3},
+		{URL: "https://example.com/a", Title: "A again", Source: "Reddit", Score: 5},
+		{URL: "https://example.com/b", Title: "B", Source: "Feed", Score: 1},
+		{Title: "No URL", Source: "Feed", Score: 9},
+	}, firstRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// The second run opens the file afresh, as the next run would
+	s = openTestStore(t, path)
+	defer s.Close()
+	if err := s.Record([]Sighting{{URL: "https://example.com/a", Title: "A, updated", Source: "Feed", Score: 4}}, secondRun); err != nil {
+		t.Fatal(err)
+	}
+
+	records := lookup(t, s, "https://example.com/a", "https://example.com/b", "https://example.com/unseen", "")
+	if len(records) != 2 {
+		t.Fatalf("Lookup returned %d records, want a and b: %+v", len(records), records)
+	}
+
+	want := Record{
+		URL:       "https://example.com/a",
+		Title:     "A, updated",
+		FirstSeen: firstRun,
+		LastSeen:  secondRun,
+		Sources:   []string{"Feed", "Reddit"},
+		Scores:    []ScorePoint{{At: firstRun, Score: 5}, {At: secondRun, Score: 4}},
+	}
+	if got := records["https://example.com/a"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("a = %+v\nwant %+v", got, want)
+	}
+	if b := records["https://example.com/b"]; !b.FirstSeen.Equal(firstRun) || !b.LastSeen.Equal(firstRun) {
+		t.Errorf("b first seen %v, last seen %v, want both at the first run", b.FirstSeen, b.LastSeen)
+	}
+
+	removed, err := s.Prune(secondRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 || len(lookup(t, s, "https://example.com/b")) != 0 {
+		t.Errorf("Prune removed %d records, want only b", removed)
+	}
+}
+
+func TestRecordCapsScores(t *testing.T) {
+	s := openTestStore(t, filepath.Join(t.TempDir(), "items.db"))
+	defer s.Close()
+
+	for i := 0; i < maxScores+5; i++ {
+		if err := s.Record([]Sighting{{URL: "https://example.com/a", Score: float64(i)}}, firstRun.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scores := lookup(t, s, "https://example.com/a")["https://example.com/a"].Scores
+	if len(scores) != maxScores || scores[0].Score != 5 {
+		t.Errorf("kept %d scores from %v, want the last %d", len(scores), scores[0].Score, maxScores)
+	}
+}
+
+func TestReadOnlyStoreDoesNotWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.db")
+	if _, err := OpenReadOnly(path); err == nil {
+		t.Error("OpenReadOnly created a missing store")
+	}
+
+	s := openTestStore(t, path)
+	if err := s.Record([]Sighting{{URL: "https://example.com/a", Source: "Feed", Score: 1}}, firstRun); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	ro, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ro.Record([]Sighting{
+		{URL: "https://example.com/a", Source: "Other", Score: 9},
+		{URL: "https://example.com/new", Source: "Feed", Score: 1},
+	}, secondRun); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := ro.Prune(secondRun.Add(time.Hour)); err != nil || removed != 0 {
+		t.Errorf("read-only Prune = %d, %v, want nothing removed", removed, err)
+	}
+	ro.Close()
+
+	s = openTestStore(t, path)
+	defer s.Close()
+	records := lookup(t, s, "https://example.com/a", "https://example.com/new")
+	a, ok := records["https://example.com/a"]
+	if len(records) != 1 || !ok {
+		t.Fatalf("records after read-only run = %+v, want only a", records)
+	}
+	if !a.LastSeen.Equal(firstRun) || len(a.Sources) != 1 || len(a.Scores) != 1 {
+		t.Errorf("a changed by a read-only run: %+v", a)
+	}
+}